@@ -0,0 +1,167 @@
+// Command arenasign passes transaction envelopes between the parties signing a
+// transaction offline. Envelopes are read from a file, or stdin when the file is
+// -, and written to stdout as JSON or hex.
+//
+//	arenasign summary tx.json
+//	arenasign sign -account admin tx.json > signed.json
+//	arenasign sign -address 0x0996b5100d5c8ad6 -key-env ADMIN_KEY -format hex tx.json
+//	arenasign convert -format hex tx.json
+//	arenasign submit -host access.devnet.nodes.onflow.org:9000 signed.json
+//
+// Keys are never taken from the command line, either from an account of the
+// flow.json given by -config or as hex from the environment variable -key-env.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+
+	"github.com/arena/arena-cadence/lib/go/flowconfig"
+	"github.com/arena/arena-cadence/lib/go/signing"
+	"github.com/onflow/flow-go-sdk"
+	"github.com/onflow/flow-go-sdk/client"
+	"github.com/onflow/flow-go-sdk/crypto"
+	"google.golang.org/grpc"
+)
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: arenasign summary|sign|convert|submit [flags] FILE\n")
+	os.Exit(2)
+}
+
+func main() {
+	log.SetFlags(0)
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	cmd, args := os.Args[1], os.Args[2:]
+	fs := flag.NewFlagSet(cmd, flag.ExitOnError)
+	format := fs.String("format", "json", "output format, json or hex")
+	configPath := fs.String("config", "", "flow.json to read -account from, defaults to the nearest one")
+	account := fs.String("account", "", "flow.json account to sign with")
+	address := fs.String("address", "", "address to sign for with -key-env")
+	keyEnv := fs.String("key-env", "", "environment variable holding the hex private key")
+	keyIndex := fs.Int("key-index", 0, "account key index of -key-env")
+	sigAlgo := fs.String("sig-algo", "ECDSA_P256", "signature algorithm of -key-env")
+	hashAlgo := fs.String("hash-algo", "SHA3_256", "hash algorithm of -key-env")
+	host := fs.String("host", "", "access node to submit to")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		usage()
+	}
+
+	env, err := readEnvelope(fs.Arg(0))
+	if err != nil {
+		log.Fatalf("Reading envelope: %v", err)
+	}
+
+	switch cmd {
+	case "summary":
+		fmt.Print(env.Summary())
+		return
+	case "convert":
+	case "sign":
+		signer, keys, err := signingKeys(*configPath, *account, *address, *keyEnv, *keyIndex, *sigAlgo, *hashAlgo)
+		if err != nil {
+			log.Fatalf("Loading key: %v", err)
+		}
+		if err := env.Sign(signer, keys...); err != nil {
+			log.Fatalf("Signing: %v", err)
+		}
+	case "submit":
+		if *host == "" {
+			log.Fatalf("-host is required")
+		}
+		c, err := client.New(*host, grpc.WithInsecure())
+		if err != nil {
+			log.Fatalf("Connecting to %s: %v", *host, err)
+		}
+		if err := env.Submit(context.Background(), c); err != nil {
+			log.Fatalf("Submitting: %v", err)
+		}
+		fmt.Println(env.Transaction.ID())
+		return
+	default:
+		usage()
+	}
+
+	if err := writeEnvelope(env, *format); err != nil {
+		log.Fatalf("Writing envelope: %v", err)
+	}
+}
+
+func readEnvelope(path string) (*signing.Envelope, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		data, err = ioutil.ReadFile(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return signing.ReadEnvelope(data)
+}
+
+func writeEnvelope(env *signing.Envelope, format string) error {
+	switch format {
+	case "json":
+		data, err := env.MarshalJSON()
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Printf("%s\n", data)
+		return err
+	case "hex":
+		_, err := fmt.Println(env.Hex())
+		return err
+	}
+	return fmt.Errorf("unknown format %q", format)
+}
+
+// signingKeys returns the address and keys to sign with, from a flow.json
+// account or a single key in the environment
+func signingKeys(configPath, account, address, keyEnv string, keyIndex int, sigAlgo, hashAlgo string) (flow.Address, []signing.Key, error) {
+	if account != "" {
+		if configPath == "" {
+			path, err := flowconfig.Find()
+			if err != nil {
+				return flow.EmptyAddress, nil, err
+			}
+			configPath = path
+		}
+		cfg, err := flowconfig.Load(configPath)
+		if err != nil {
+			return flow.EmptyAddress, nil, err
+		}
+		acct, err := cfg.Account(account)
+		if err != nil {
+			return flow.EmptyAddress, nil, err
+		}
+		configs, err := acct.KeyConfigs()
+		if err != nil {
+			return flow.EmptyAddress, nil, err
+		}
+		keys := make([]signing.Key, len(configs))
+		for i, c := range configs {
+			keys[i] = c.Key()
+		}
+		return acct.Address, keys, nil
+	}
+
+	if address == "" || keyEnv == "" {
+		return flow.EmptyAddress, nil, fmt.Errorf("either -account or -address and -key-env are required")
+	}
+	priv, err := crypto.DecodePrivateKeyHex(crypto.StringToSignatureAlgorithm(sigAlgo), os.Getenv(keyEnv))
+	if err != nil {
+		return flow.EmptyAddress, nil, fmt.Errorf("decoding %s: %w", keyEnv, err)
+	}
+	c := signing.KeyConfig{Index: keyIndex, PrivateKey: priv, HashAlgo: crypto.StringToHashAlgorithm(hashAlgo)}
+	return flow.HexToAddress(address), []signing.Key{c.Key()}, nil
+}
@@ -6,5 +6,6 @@ require (
 	github.com/davecgh/go-spew v1.1.1
 	github.com/onflow/cadence v0.15.0
 	github.com/onflow/flow-go-sdk v0.20.0
+	go.etcd.io/bbolt v1.3.6
 	google.golang.org/grpc v1.31.1
 )
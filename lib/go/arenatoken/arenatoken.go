@@ -2,6 +2,8 @@ package arenatoken
 
 import (
 	_ "embed"
+	"fmt"
+	"sync"
 
 	"github.com/onflow/flow-go-sdk"
 )
@@ -10,18 +12,145 @@ import (
 // to interact with the token contract
 type ArenaToken struct {
 	contracts map[string]flow.Address
+
+	// values templates are rendered with
+	values templateValues
+
+	// err is the first invalid option, returned by every builder
+	err error
+
+	// gasLimit overrides the gas limit of every transaction when non-zero
+	gasLimit uint64
+
+	// estimator and margin used by Estimate
+	estimator ComputationEstimator
+	margin    float64
+
+	// rendered scripts keyed by template path
+	mu      sync.Mutex
+	scripts map[string]string
+}
+
+// Option configures an ArenaToken handle
+type Option func(*ArenaToken)
+
+// WithGasLimit sets an explicit gas limit on every transaction instead of the
+// limit declared by its template
+func WithGasLimit(limit uint64) Option {
+	return func(r *ArenaToken) {
+		r.gasLimit = limit
+	}
+}
+
+// WithContractName sets the name the token contract is deployed with, for
+// deployments other than ArenaToken. Templates import and reference the token
+// contract by this name. An address registered for the name by WithImport
+// takes precedence over the address passed to New.
+func WithContractName(name string) Option {
+	return func(r *ArenaToken) {
+		if !identifierRe.MatchString(name) {
+			r.fail(fmt.Errorf("%w: contract name %q is not an identifier", ErrInvalidOption, name))
+			return
+		}
+		addr := r.contracts[r.values.Contract]
+		delete(r.contracts, r.values.Contract)
+		r.values.Contract = name
+		// keep the address of a contract imported under the new name
+		if _, ok := r.contracts[name]; !ok {
+			r.contracts[name] = addr
+		}
+	}
+}
+
+// WithPaths sets the storage and capability paths of the token contract. Empty
+// fields keep their default path.
+func WithPaths(paths Paths) Option {
+	return func(r *ArenaToken) {
+		r.values.Paths = paths.withDefaults()
+		if err := r.values.Paths.validate(); err != nil {
+			r.fail(err)
+		}
+	}
+}
+
+// WithImport registers the address of an additional contract that templates can
+// import, or overrides the address of a known one
+func WithImport(name string, addr flow.Address) Option {
+	return func(r *ArenaToken) {
+		if !identifierRe.MatchString(name) {
+			r.fail(fmt.Errorf("%w: contract name %q is not an identifier", ErrInvalidOption, name))
+			return
+		}
+		r.contracts[name] = addr
+	}
+}
+
+// WithEstimator sets the estimator used by Estimate along with the safety margin
+// added to its estimates, e.g. a margin of 0.2 allows 20% more computation.
+func WithEstimator(estimator ComputationEstimator, margin float64) Option {
+	return func(r *ArenaToken) {
+		r.estimator = estimator
+		r.margin = margin
+	}
 }
 
-func New(contractAddr, fungibleTokenAddr flow.Address) *ArenaToken {
+// New returns a handle for the token contract deployed at contractAddr that
+// imports FungibleToken from fungibleTokenAddr
+func New(contractAddr, fungibleTokenAddr flow.Address, opts ...Option) *ArenaToken {
 	contracts := make(map[string]flow.Address)
-	contracts["ArenaToken"] = contractAddr
+	contracts[DefaultContractName] = contractAddr
 	contracts["FungibleToken"] = fungibleTokenAddr
 
-	return &ArenaToken{contracts: contracts}
+	r := &ArenaToken{
+		contracts: contracts,
+		values:    defaultValues,
+		scripts:   make(map[string]string),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// fail records the first invalid option
+func (r *ArenaToken) fail(err error) {
+	if r.err == nil {
+		r.err = err
+	}
+}
+
+// ContractName returns the name the token contract is deployed with
+func (r *ArenaToken) ContractName() string {
+	return r.values.Contract
 }
 
-// Contract returns the source for deploying the ArenaToken fungible token contract
+// ContractAddress returns the address the token contract is deployed to
+func (r *ArenaToken) ContractAddress() flow.Address {
+	return r.contracts[r.values.Contract]
+}
+
+// ContractCode returns the source for deploying the token contract with the name
+// and paths of the handle
+func (r *ArenaToken) ContractCode() (string, error) {
+	if r.err != nil {
+		return "", r.err
+	}
+	return render(lookupTemplate(contractPath), r.values, r.contracts)
+}
+
+// Contract returns the source for deploying the ArenaToken fungible token contract.
+// It panics if the contract template cannot be rendered, use RenderContract to
+// handle the error instead.
 func Contract(fungibleTokenAddr flow.Address) string {
+	source, err := RenderContract(fungibleTokenAddr)
+	if err != nil {
+		panic(err)
+	}
+	return source
+}
+
+// RenderContract returns the source for deploying the ArenaToken fungible token contract
+func RenderContract(fungibleTokenAddr flow.Address) (string, error) {
 	contracts := map[string]flow.Address{"FungibleToken": fungibleTokenAddr}
-	return render(contractTemplate, nil, contracts)
+	return render(lookupTemplate(contractPath), defaultValues, contracts)
 }
@@ -0,0 +1,170 @@
+package arenatoken
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/cadence/runtime/ast"
+)
+
+var (
+	// ErrArgumentCount is wrapped by an ArgumentError when a builder passes a
+	// different number of arguments than its template declares
+	ErrArgumentCount = errors.New("wrong number of arguments")
+	// ErrArgumentType is wrapped by an ArgumentError when an argument does not
+	// conform to the type of its parameter
+	ErrArgumentType = errors.New("argument type mismatch")
+)
+
+// ArgumentError is returned when the arguments passed to a builder do not match
+// the parameters declared by its template
+type ArgumentError struct {
+	// Template is the path of the template within the embedded fs
+	Template string
+	// Index and Param identify the mismatched argument, they are unset when
+	// the number of arguments is wrong
+	Index int
+	Param Param
+	Err   error
+}
+
+func (e *ArgumentError) Error() string {
+	if errors.Is(e.Err, ErrArgumentCount) {
+		return fmt.Sprintf("arguments to %s: %v", e.Template, e.Err)
+	}
+	return fmt.Sprintf("argument %d to %s (%s: %s): %v", e.Index, e.Template, e.Param.Name, e.Param.Type, e.Err)
+}
+
+func (e *ArgumentError) Unwrap() error {
+	return e.Err
+}
+
+// checkArguments validates the provided arguments against the parameters of the template
+func (t *cadenceTemplate) checkArguments(args []cadence.Value) error {
+	if len(args) != len(t.sig.Params) {
+		return &ArgumentError{
+			Template: t.path,
+			Err:      fmt.Errorf("%w: expected %d, got %d", ErrArgumentCount, len(t.sig.Params), len(args)),
+		}
+	}
+	for i, arg := range args {
+		if err := checkValue(arg, t.paramTypes[i]); err != nil {
+			return &ArgumentError{Template: t.path, Index: i, Param: t.sig.Params[i], Err: err}
+		}
+	}
+	return nil
+}
+
+// checkValue reports whether v conforms to the declared cadence type
+func checkValue(v cadence.Value, typ ast.Type) error {
+	mismatch := func() error {
+		return fmt.Errorf("%w: got %s", ErrArgumentType, describe(v))
+	}
+
+	switch typ := typ.(type) {
+	case *ast.OptionalType:
+		// non-optional values are subtypes of their optional type
+		if opt, ok := v.(cadence.Optional); ok {
+			if opt.Value == nil {
+				return nil
+			}
+			return checkValue(opt.Value, typ.Type)
+		}
+		return checkValue(v, typ.Type)
+
+	case *ast.VariableSizedType:
+		arr, ok := v.(cadence.Array)
+		if !ok {
+			return mismatch()
+		}
+		for i, elem := range arr.Values {
+			if err := checkValue(elem, typ.Type); err != nil {
+				return fmt.Errorf("element %d: %w", i, err)
+			}
+		}
+		return nil
+
+	case *ast.ConstantSizedType:
+		arr, ok := v.(cadence.Array)
+		if !ok || typ.Size == nil || int64(len(arr.Values)) != typ.Size.Value.Int64() {
+			return mismatch()
+		}
+		for i, elem := range arr.Values {
+			if err := checkValue(elem, typ.Type); err != nil {
+				return fmt.Errorf("element %d: %w", i, err)
+			}
+		}
+		return nil
+
+	case *ast.DictionaryType:
+		dict, ok := v.(cadence.Dictionary)
+		if !ok {
+			return mismatch()
+		}
+		for _, pair := range dict.Pairs {
+			if err := checkValue(pair.Key, typ.KeyType); err != nil {
+				return fmt.Errorf("key %s: %w", pair.Key, err)
+			}
+			if err := checkValue(pair.Value, typ.ValueType); err != nil {
+				return fmt.Errorf("value for %s: %w", pair.Key, err)
+			}
+		}
+		return nil
+
+	case *ast.NominalType:
+		if nominalMatches(v, typ.String()) {
+			return nil
+		}
+		return mismatch()
+	}
+
+	return fmt.Errorf("%w: unsupported parameter type %s", ErrArgumentType, typ)
+}
+
+func nominalMatches(v cadence.Value, name string) bool {
+	if name == "AnyStruct" {
+		return true
+	}
+	switch v := v.(type) {
+	case nil, cadence.Optional, cadence.Array, cadence.Dictionary:
+		return false
+	case cadence.Path:
+		switch name {
+		case "Path":
+			return true
+		case "StoragePath":
+			return v.Domain == "storage"
+		case "PublicPath":
+			return v.Domain == "public"
+		case "PrivatePath":
+			return v.Domain == "private"
+		case "CapabilityPath":
+			return v.Domain == "public" || v.Domain == "private"
+		}
+		return false
+	case cadence.Struct, cadence.Resource, cadence.Event:
+		// composite type ids are qualified by their location, i.e. A.0x1.Contract.Type
+		id := v.Type().ID()
+		return id == name || strings.HasSuffix(id, "."+name)
+	}
+	return v.Type().ID() == name
+}
+
+func describe(v cadence.Value) string {
+	switch v := v.(type) {
+	case nil:
+		return "nil"
+	case cadence.Optional:
+		if v.Value == nil {
+			return "nil"
+		}
+		return describe(v.Value) + "?"
+	case cadence.Array:
+		return "array"
+	case cadence.Dictionary:
+		return "dictionary"
+	}
+	return v.Type().ID()
+}
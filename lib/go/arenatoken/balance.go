@@ -1,19 +1,29 @@
 package arenatoken
 
 import (
+	"context"
 	_ "embed"
 
 	"github.com/onflow/cadence"
 	"github.com/onflow/flow-go-sdk"
 )
 
-// Balance returns a script for fetching the ArenaToken balance of the provided account
+// Balance returns a script for fetching the ArenaToken balance of the provided account.
+// It panics if the script cannot be rendered, see BuildBalance.
 func (r *ArenaToken) Balance(target flow.Address) ([]byte, []cadence.Value) {
+	req, err := r.BuildBalance(target)
+	if err != nil {
+		panic(err)
+	}
+	return req.Script, req.Arguments
+}
 
-	var arg cadence.Address
-	copy(arg[:], target.Bytes())
-
-	script := render(balanceTemplate, nil, r.contracts)
-
-	return []byte(script), []cadence.Value{arg}
+// AccountBalance returns the balance of account. It fails for accounts that
+// aren't set up, see VaultStatus.
+func (r *ArenaToken) AccountBalance(ctx context.Context, client ScriptExecutor, account flow.Address) (cadence.UFix64, error) {
+	req, err := r.BuildBalance(account)
+	if err != nil {
+		return 0, err
+	}
+	return executeUFix64(ctx, client, req)
 }
@@ -0,0 +1,82 @@
+package arenatoken
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go-sdk"
+)
+
+// DefaultBalanceChunk is the number of accounts whose balances are read by one
+// script execution. It keeps the arguments well under the access api message
+// size and the borrows under the script computation limit.
+const DefaultBalanceChunk = 200
+
+// Balances returns the balances of the accounts, reading chunkSize accounts per
+// script execution, DefaultBalanceChunk if zero. Accounts that aren't set up
+// are left out of the result.
+func (r *ArenaToken) Balances(ctx context.Context, client ScriptExecutor, accounts []flow.Address, chunkSize int) (map[flow.Address]cadence.UFix64, error) {
+	if chunkSize <= 0 {
+		chunkSize = DefaultBalanceChunk
+	}
+
+	// read each account once
+	seen := make(map[flow.Address]bool, len(accounts))
+	unique := make([]flow.Address, 0, len(accounts))
+	for _, addr := range accounts {
+		if !seen[addr] {
+			seen[addr] = true
+			unique = append(unique, addr)
+		}
+	}
+
+	balances := make(map[flow.Address]cadence.UFix64, len(unique))
+	for start := 0; start < len(unique); start += chunkSize {
+		end := start + chunkSize
+		if end > len(unique) {
+			end = len(unique)
+		}
+		req, err := r.BuildBalances(unique[start:end])
+		if err != nil {
+			return nil, err
+		}
+		v, err := client.ExecuteScriptAtLatestBlock(ctx, req.Script, req.Arguments)
+		if err != nil {
+			return nil, fmt.Errorf("executing %s for accounts %d-%d: %w", req.Template, start, end-1, err)
+		}
+		if err := decodeBalances(v, balances); err != nil {
+			return nil, err
+		}
+	}
+	return balances, nil
+}
+
+// DecodeBalances returns the balances of a Balances script result
+func DecodeBalances(v cadence.Value) (map[flow.Address]cadence.UFix64, error) {
+	balances := make(map[flow.Address]cadence.UFix64)
+	if err := decodeBalances(v, balances); err != nil {
+		return nil, err
+	}
+	return balances, nil
+}
+
+// decodeBalances adds the balances of a Balances script result to balances
+func decodeBalances(v cadence.Value, balances map[flow.Address]cadence.UFix64) error {
+	dict, ok := v.(cadence.Dictionary)
+	if !ok {
+		return fmt.Errorf("%w: expected {Address: UFix64}, got %T", ErrResultType, v)
+	}
+	for _, pair := range dict.Pairs {
+		addr, ok := pair.Key.(cadence.Address)
+		if !ok {
+			return fmt.Errorf("%w: expected Address key, got %T", ErrResultType, pair.Key)
+		}
+		amount, err := DecodeUFix64(pair.Value)
+		if err != nil {
+			return err
+		}
+		balances[flow.BytesToAddress(addr.Bytes())] = amount
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package arenatoken
+
+import (
+	"context"
+	"testing"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go-sdk"
+	"google.golang.org/grpc"
+)
+
+// balancesExecutor answers Balances scripts with a balance of 1.0 for every
+// account except unset, counting executions
+type balancesExecutor struct {
+	unset flow.Address
+	calls int
+}
+
+func (e *balancesExecutor) ExecuteScriptAtLatestBlock(_ context.Context, _ []byte, args []cadence.Value, _ ...grpc.CallOption) (cadence.Value, error) {
+	e.calls++
+	one, _ := cadence.NewUFix64("1.0")
+	var pairs []cadence.KeyValuePair
+	for _, v := range args[0].(cadence.Array).Values {
+		if flow.BytesToAddress(v.(cadence.Address).Bytes()) != e.unset {
+			pairs = append(pairs, cadence.KeyValuePair{Key: v, Value: one})
+		}
+	}
+	return cadence.NewDictionary(pairs), nil
+}
+
+func TestBalances(t *testing.T) {
+	r := New(benchArenaTokenAddr, benchFungibleTokenAddr)
+
+	var accounts []flow.Address
+	for i := 1; i <= 25; i++ {
+		accounts = append(accounts, flow.BytesToAddress([]byte{byte(i)}))
+	}
+	// duplicates are read once
+	accounts = append(accounts, accounts[0], accounts[1])
+
+	client := &balancesExecutor{unset: accounts[3]}
+	balances, err := r.Balances(context.Background(), client, accounts, 10)
+	if err != nil {
+		t.Fatalf("Balances: %v", err)
+	}
+	if client.calls != 3 {
+		t.Fatalf("Expected 3 chunks, got: %d", client.calls)
+	}
+	if len(balances) != 24 || balances[accounts[24]].String() != "1.00000000" {
+		t.Fatalf("Expected 24 balances, got: %v", balances)
+	}
+	if _, ok := balances[accounts[3]]; ok {
+		t.Fatalf("Expected unset account to be left out")
+	}
+}
@@ -0,0 +1,153 @@
+// Code generated by buildergen from the embedded cadence directory. DO NOT EDIT.
+
+package arenatoken
+
+import (
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go-sdk"
+)
+
+// ensure imports are used by every generated file
+var (
+	_ cadence.Value
+	_ flow.Address
+)
+
+// BuildBalance returns a script rendered from cadence/scripts/arenaToken/balance.cdc
+func (r *ArenaToken) BuildBalance(account flow.Address) (*ScriptRequest, error) {
+	return r.scriptRequest(lookupTemplate("cadence/scripts/arenaToken/balance.cdc"), cadenceAddress(account))
+}
+
+// BuildBalances returns a script rendered from cadence/scripts/arenaToken/balances.cdc
+//
+// Returns the balances of the accounts keyed by address. Accounts whose balance
+// capability isn't linked are left out.
+func (r *ArenaToken) BuildBalances(accounts []flow.Address) (*ScriptRequest, error) {
+	return r.scriptRequest(lookupTemplate("cadence/scripts/arenaToken/balances.cdc"), cadenceAddresses(accounts))
+}
+
+// BuildBatchTransfer returns an unsigned transaction rendered from cadence/transactions/arenaToken/batch_transfer.cdc
+//
+// Transfers the amount of each recipient, withdrawing their total once.
+// Recipients without a receiver abort the batch, unless skipMissing is set in
+// which case their amount stays with the signer and a BatchRecipientSkipped
+// event reports them. The gas limit covers MaxBatchRecipients recipients.
+func (r *ArenaToken) BuildBatchTransfer(amounts map[flow.Address]cadence.UFix64, skipMissing bool) (*TransactionRequest, error) {
+	return r.transaction(lookupTemplate("cadence/transactions/arenaToken/batch_transfer.cdc"), 9100, cadenceAmounts(amounts), cadence.NewBool(skipMissing))
+}
+
+// BuildBatchTransferArrays returns an unsigned transaction rendered from cadence/transactions/arenaToken/batch_transfer_arrays.cdc
+//
+// Transfers amounts[i] to recipients[i], withdrawing their total once. A
+// recipient listed more than once receives the sum of its amounts. Recipients
+// without a receiver abort the batch, unless skipMissing is set in which case
+// their amount stays with the signer and a BatchRecipientSkipped event reports
+// them. The gas limit covers MaxBatchRecipients recipients.
+func (r *ArenaToken) BuildBatchTransferArrays(recipients []flow.Address, amounts []cadence.UFix64, skipMissing bool) (*TransactionRequest, error) {
+	return r.transaction(lookupTemplate("cadence/transactions/arenaToken/batch_transfer_arrays.cdc"), 9100, cadenceAddresses(recipients), cadenceUFix64s(amounts), cadence.NewBool(skipMissing))
+}
+
+// BuildBurn returns an unsigned transaction rendered from cadence/transactions/arenaToken/burn_arena.cdc
+func (r *ArenaToken) BuildBurn(amount cadence.UFix64) (*TransactionRequest, error) {
+	return r.transaction(lookupTemplate("cadence/transactions/arenaToken/burn_arena.cdc"), 40, amount)
+}
+
+// BuildCirculatingSupply returns a script rendered from cadence/scripts/arenaToken/circulating_supply.cdc
+//
+// Returns the total supply less the balances held by the excluded accounts.
+// Accounts without a balance capability hold no tokens and each account is
+// counted once.
+func (r *ArenaToken) BuildCirculatingSupply(excluded []flow.Address) (*ScriptRequest, error) {
+	return r.scriptRequest(lookupTemplate("cadence/scripts/arenaToken/circulating_supply.cdc"), cadenceAddresses(excluded))
+}
+
+// BuildClaimAdministrator returns an unsigned transaction rendered from cadence/transactions/arenaToken/claim_admin.cdc
+// with newAdmin as its authorizers, in that order
+func (r *ArenaToken) BuildClaimAdministrator(newAdmin flow.Address) (*TransactionRequest, error) {
+	req, err := r.transaction(lookupTemplate("cadence/transactions/arenaToken/claim_admin.cdc"), 60)
+	if err != nil {
+		return nil, err
+	}
+	if err := req.Authorize(newAdmin); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// BuildDestroyAdministrator returns an unsigned transaction rendered from cadence/transactions/arenaToken/destroy_admin.cdc
+func (r *ArenaToken) BuildDestroyAdministrator() (*TransactionRequest, error) {
+	return r.transaction(lookupTemplate("cadence/transactions/arenaToken/destroy_admin.cdc"), 40)
+}
+
+// BuildInspectAccount returns a script rendered from cadence/scripts/arenaToken/inspect_account.cdc
+//
+// Returns a report of the vault links of an account
+func (r *ArenaToken) BuildInspectAccount(account flow.Address) (*ScriptRequest, error) {
+	return r.scriptRequest(lookupTemplate("cadence/scripts/arenaToken/inspect_account.cdc"), cadenceAddress(account))
+}
+
+// BuildMintTokens returns an unsigned transaction rendered from cadence/transactions/arenaToken/mint_arena.cdc
+func (r *ArenaToken) BuildMintTokens(recipient flow.Address, amount cadence.UFix64) (*TransactionRequest, error) {
+	return r.transaction(lookupTemplate("cadence/transactions/arenaToken/mint_arena.cdc"), 60, cadenceAddress(recipient), amount)
+}
+
+// BuildOfferAdministrator returns an unsigned transaction rendered from cadence/transactions/arenaToken/offer_admin.cdc
+// with currentAdmin as its authorizers, in that order
+func (r *ArenaToken) BuildOfferAdministrator(currentAdmin flow.Address, recipient flow.Address) (*TransactionRequest, error) {
+	req, err := r.transaction(lookupTemplate("cadence/transactions/arenaToken/offer_admin.cdc"), 40, cadenceAddress(recipient))
+	if err != nil {
+		return nil, err
+	}
+	if err := req.Authorize(currentAdmin); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// BuildRepairAccount returns an unsigned transaction rendered from cadence/transactions/arenaToken/repair_account.cdc
+func (r *ArenaToken) BuildRepairAccount() (*TransactionRequest, error) {
+	return r.transaction(lookupTemplate("cadence/transactions/arenaToken/repair_account.cdc"), 100)
+}
+
+// BuildSendArena returns an unsigned transaction rendered from cadence/transactions/arenaToken/send_arena.cdc
+func (r *ArenaToken) BuildSendArena(amount cadence.UFix64, to flow.Address) (*TransactionRequest, error) {
+	return r.transaction(lookupTemplate("cadence/transactions/arenaToken/send_arena.cdc"), defaultGasLimit, amount, cadenceAddress(to))
+}
+
+// BuildSetupAccount returns an unsigned transaction rendered from cadence/transactions/arenaToken/setup_account.cdc
+func (r *ArenaToken) BuildSetupAccount() (*TransactionRequest, error) {
+	return r.transaction(lookupTemplate("cadence/transactions/arenaToken/setup_account.cdc"), 100)
+}
+
+// BuildTotalSupply returns a script rendered from cadence/scripts/arenaToken/total_supply.cdc
+//
+// Returns the total supply of the token
+func (r *ArenaToken) BuildTotalSupply() (*ScriptRequest, error) {
+	return r.scriptRequest(lookupTemplate("cadence/scripts/arenaToken/total_supply.cdc"))
+}
+
+// BuildTransfer returns an unsigned transaction rendered from cadence/transactions/arenaToken/transfer.cdc
+func (r *ArenaToken) BuildTransfer(to flow.Address, amount cadence.UFix64) (*TransactionRequest, error) {
+	return r.transaction(lookupTemplate("cadence/transactions/arenaToken/transfer.cdc"), 40, cadenceAddress(to), amount)
+}
+
+// BuildTransferAdministrator returns an unsigned transaction rendered from cadence/transactions/arenaToken/transfer_admin.cdc
+// with currentAdmin, newAdmin as its authorizers, in that order
+func (r *ArenaToken) BuildTransferAdministrator(currentAdmin flow.Address, newAdmin flow.Address) (*TransactionRequest, error) {
+	req, err := r.transaction(lookupTemplate("cadence/transactions/arenaToken/transfer_admin.cdc"), 40)
+	if err != nil {
+		return nil, err
+	}
+	if err := req.Authorize(currentAdmin, newAdmin); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// BuildVaultStatus returns a script rendered from cadence/scripts/arenaToken/vault_status.cdc
+//
+// Returns the vault status of an account without panicking when it isn't set up.
+// A vault whose balance capability isn't linked reads as missing.
+func (r *ArenaToken) BuildVaultStatus(account flow.Address) (*ScriptRequest, error) {
+	return r.scriptRequest(lookupTemplate("cadence/scripts/arenaToken/vault_status.cdc"), cadenceAddress(account))
+}
@@ -0,0 +1,19 @@
+package arenatoken
+
+import (
+	"github.com/onflow/flow-go-sdk"
+)
+
+// ClaimAdministrator returns an unsigned transaction that moves an Admin resource
+// previously offered with OfferAdministrator from the storage of the contract
+// account into the storage of newAdmin, which is the only authorizer. newAdmin
+// proves its address with a private AdministratorClaimer capability, linked at
+// the AdminClaimerPrivate path by the transaction. It panics if the transaction cannot be built, see
+// BuildClaimAdministrator.
+func (r *ArenaToken) ClaimAdministrator(newAdmin flow.Address) *flow.Transaction {
+	req, err := r.BuildClaimAdministrator(newAdmin)
+	if err != nil {
+		panic(err)
+	}
+	return req.Transaction
+}
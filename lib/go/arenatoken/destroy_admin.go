@@ -8,10 +8,11 @@ import (
 
 // DestroyAdministrator returns an unsigned transaction for destroying the singular
 // Admin resource. This will prevent any future minters from being created.
+// It panics if the transaction cannot be built, see BuildDestroyAdministrator.
 func (r *ArenaToken) DestroyAdministrator() *flow.Transaction {
-	tx := render(destroyAdministratorTemplate, nil, r.contracts)
-
-	return flow.NewTransaction().
-		SetScript([]byte(tx)).
-		SetGasLimit(40)
+	req, err := r.BuildDestroyAdministrator()
+	if err != nil {
+		panic(err)
+	}
+	return req.Transaction
 }
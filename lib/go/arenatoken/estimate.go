@@ -0,0 +1,41 @@
+package arenatoken
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// ErrNoEstimator is returned by Estimate when the handle has no estimator configured
+var ErrNoEstimator = errors.New("no computation estimator configured")
+
+// MaxGasLimit is the highest gas limit accepted by the network
+const MaxGasLimit = 9999
+
+// ComputationEstimator reports the computation used by executing a transaction
+type ComputationEstimator interface {
+	EstimateComputation(ctx context.Context, req *TransactionRequest) (uint64, error)
+}
+
+// Estimate sets the gas limit of the transaction to the computation reported by
+// the estimator configured with WithEstimator plus its safety margin, capped at
+// MaxGasLimit. It replaces any limit set by the builder, including one set with
+// WithGasLimit.
+func (r *ArenaToken) Estimate(ctx context.Context, req *TransactionRequest) error {
+	if r.estimator == nil {
+		return ErrNoEstimator
+	}
+
+	used, err := r.estimator.EstimateComputation(ctx, req)
+	if err != nil {
+		return fmt.Errorf("estimating %s: %w", req.Template, err)
+	}
+
+	limit := uint64(math.Ceil(float64(used) * (1 + r.margin)))
+	if limit > MaxGasLimit {
+		limit = MaxGasLimit
+	}
+	req.Transaction.SetGasLimit(limit)
+	return nil
+}
@@ -0,0 +1,168 @@
+package arenatoken
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go-sdk"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrSporkRange is wrapped by the error returned when a script is executed at a
+// block the access node doesn't hold, such as a block of an earlier spork
+var ErrSporkRange = errors.New("block outside the access node's spork range")
+
+// BlockRef selects the block a script is executed at. The zero value is the
+// latest sealed block.
+type BlockRef struct {
+	height   uint64
+	id       flow.Identifier
+	byID     bool
+	byHeight bool
+}
+
+// LatestBlock returns a reference to the latest sealed block
+func LatestBlock() BlockRef {
+	return BlockRef{}
+}
+
+// AtHeight returns a reference to the block at height
+func AtHeight(height uint64) BlockRef {
+	return BlockRef{height: height, byHeight: true}
+}
+
+// AtBlockID returns a reference to the block with id
+func AtBlockID(id flow.Identifier) BlockRef {
+	return BlockRef{id: id, byID: true}
+}
+
+// Height returns the height of the reference, if it selects a block by height
+func (b BlockRef) Height() (uint64, bool) {
+	return b.height, b.byHeight
+}
+
+// ID returns the block id of the reference, if it selects a block by id
+func (b BlockRef) ID() (flow.Identifier, bool) {
+	return b.id, b.byID
+}
+
+// IsLatest reports whether the reference selects the latest sealed block
+func (b BlockRef) IsLatest() bool {
+	return !b.byHeight && !b.byID
+}
+
+func (b BlockRef) String() string {
+	switch {
+	case b.byHeight:
+		return fmt.Sprintf("height %d", b.height)
+	case b.byID:
+		return fmt.Sprintf("block %s", b.id)
+	default:
+		return "latest block"
+	}
+}
+
+// SporkRangeError is returned when the access node doesn't hold the block a
+// script is executed at
+type SporkRangeError struct {
+	Block BlockRef
+	// LatestHeight is the latest sealed height of the access node, zero if it
+	// couldn't be read
+	LatestHeight uint64
+	// Err is the error returned by the access node
+	Err error
+}
+
+func (e *SporkRangeError) Error() string {
+	height, byHeight := e.Block.Height()
+	switch {
+	case byHeight && e.LatestHeight > 0 && height > e.LatestHeight:
+		return fmt.Sprintf("%v: %s is past the latest sealed height %d", ErrSporkRange, e.Block, e.LatestHeight)
+	case byHeight && e.LatestHeight == 0:
+		return fmt.Sprintf("%v: %s is unknown to the access node, its latest sealed height couldn't be read to tell whether it is past the tip or in another spork: %v",
+			ErrSporkRange, e.Block, e.Err)
+	case byHeight:
+		return fmt.Sprintf("%v: %s is before the first block of the access node's spork, query an access node of the spork that sealed it: %v",
+			ErrSporkRange, e.Block, e.Err)
+	default:
+		return fmt.Sprintf("%v: %s is unknown to the access node, it may belong to another spork: %v", ErrSporkRange, e.Block, e.Err)
+	}
+}
+
+func (e *SporkRangeError) Is(target error) bool {
+	return target == ErrSporkRange
+}
+
+func (e *SporkRangeError) Unwrap() error {
+	return e.Err
+}
+
+// BlockScriptExecutor is the subset of the flow access api used to execute
+// scripts at past blocks
+type BlockScriptExecutor interface {
+	ScriptExecutor
+	ExecuteScriptAtBlockHeight(ctx context.Context, height uint64, script []byte, arguments []cadence.Value, opts ...grpc.CallOption) (cadence.Value, error)
+	ExecuteScriptAtBlockID(ctx context.Context, blockID flow.Identifier, script []byte, arguments []cadence.Value, opts ...grpc.CallOption) (cadence.Value, error)
+	GetLatestBlockHeader(ctx context.Context, isSealed bool, opts ...grpc.CallOption) (*flow.BlockHeader, error)
+}
+
+// ExecuteScript executes the script of req at block. Blocks the access node
+// doesn't hold are reported with a SporkRangeError.
+func ExecuteScript(ctx context.Context, client BlockScriptExecutor, block BlockRef, req *ScriptRequest) (cadence.Value, error) {
+	v, err := executeAt(ctx, client, block, req.Script, req.Arguments)
+	if err != nil {
+		return nil, fmt.Errorf("executing %s at %s: %w", req.Template, block, err)
+	}
+	return v, nil
+}
+
+// Pin returns a ScriptExecutor executing every script at block rather than at
+// the latest block, so that TotalSupply, CirculatingSupply, AccountBalance,
+// Balances, VaultStatus and InspectAccount report past state:
+//
+//	supply, err := arenaToken.TotalSupply(ctx, arenatoken.Pin(flowClient, arenatoken.AtHeight(h)))
+func Pin(client BlockScriptExecutor, block BlockRef) ScriptExecutor {
+	return &pinnedExecutor{client: client, block: block}
+}
+
+// pinnedExecutor executes scripts at a fixed block
+type pinnedExecutor struct {
+	client BlockScriptExecutor
+	block  BlockRef
+}
+
+func (p *pinnedExecutor) ExecuteScriptAtLatestBlock(ctx context.Context, script []byte, arguments []cadence.Value, opts ...grpc.CallOption) (cadence.Value, error) {
+	return executeAt(ctx, p.client, p.block, script, arguments, opts...)
+}
+
+// executeAt executes script at block, wrapping not found errors of past blocks
+// in a SporkRangeError
+func executeAt(ctx context.Context, client BlockScriptExecutor, block BlockRef, script []byte, arguments []cadence.Value, opts ...grpc.CallOption) (cadence.Value, error) {
+	var (
+		v   cadence.Value
+		err error
+	)
+	if height, ok := block.Height(); ok {
+		v, err = client.ExecuteScriptAtBlockHeight(ctx, height, script, arguments, opts...)
+	} else if id, ok := block.ID(); ok {
+		v, err = client.ExecuteScriptAtBlockID(ctx, id, script, arguments, opts...)
+	} else {
+		return client.ExecuteScriptAtLatestBlock(ctx, script, arguments, opts...)
+	}
+	if err == nil {
+		return v, nil
+	}
+	if code := status.Code(err); code != codes.NotFound && code != codes.OutOfRange {
+		return nil, err
+	}
+
+	rangeErr := &SporkRangeError{Block: block, Err: err}
+	if header, headerErr := client.GetLatestBlockHeader(ctx, true); headerErr == nil {
+		rangeErr.LatestHeight = header.Height
+	}
+	return nil, rangeErr
+}
@@ -0,0 +1,95 @@
+package arenatoken
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go-sdk"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// historyExecutor holds the total supply at heights first to latest, under the
+// block id of each height. The latest header can't be read when headerErr is set.
+type historyExecutor struct {
+	first, latest uint64
+	headerErr     error
+}
+
+func (e *historyExecutor) supply(height uint64) (cadence.Value, error) {
+	if height < e.first || height > e.latest {
+		return nil, status.Errorf(codes.NotFound, "could not find block at height %d", height)
+	}
+	return cadence.UFix64(height * 100000000), nil
+}
+
+func (e *historyExecutor) ExecuteScriptAtLatestBlock(context.Context, []byte, []cadence.Value, ...grpc.CallOption) (cadence.Value, error) {
+	return e.supply(e.latest)
+}
+
+func (e *historyExecutor) ExecuteScriptAtBlockHeight(_ context.Context, height uint64, _ []byte, _ []cadence.Value, _ ...grpc.CallOption) (cadence.Value, error) {
+	return e.supply(height)
+}
+
+func (e *historyExecutor) ExecuteScriptAtBlockID(_ context.Context, id flow.Identifier, _ []byte, _ []cadence.Value, _ ...grpc.CallOption) (cadence.Value, error) {
+	return e.supply(uint64(id[0]))
+}
+
+func (e *historyExecutor) GetLatestBlockHeader(context.Context, bool, ...grpc.CallOption) (*flow.BlockHeader, error) {
+	if e.headerErr != nil {
+		return nil, e.headerErr
+	}
+	return &flow.BlockHeader{Height: e.latest}, nil
+}
+
+func TestExecuteAtBlock(t *testing.T) {
+	r := New(benchArenaTokenAddr, benchFungibleTokenAddr)
+	client := &historyExecutor{first: 10, latest: 20}
+
+	for _, test := range []struct {
+		block  BlockRef
+		supply string
+	}{
+		{LatestBlock(), "20.00000000"},
+		{AtHeight(12), "12.00000000"},
+		{AtBlockID(flow.Identifier{15}), "15.00000000"},
+	} {
+		supply, err := r.TotalSupply(context.Background(), Pin(client, test.block))
+		if err != nil || supply.String() != test.supply {
+			t.Fatalf("Expected supply %v at %s, got: %v %v", test.supply, test.block, supply, err)
+		}
+	}
+
+	req, err := r.BuildTotalSupply()
+	if err != nil {
+		t.Fatalf("BuildTotalSupply: %v", err)
+	}
+	for _, test := range []struct {
+		block   BlockRef
+		message string
+	}{
+		{AtHeight(5), "before the first block"},
+		{AtHeight(25), "past the latest sealed height 20"},
+		{AtBlockID(flow.Identifier{30}), "unknown to the access node"},
+	} {
+		_, err := ExecuteScript(context.Background(), client, test.block, req)
+		var rangeErr *SporkRangeError
+		if !errors.Is(err, ErrSporkRange) || !errors.As(err, &rangeErr) || !strings.Contains(err.Error(), test.message) {
+			t.Fatalf("Expected spork range error containing %q at %s, got: %v", test.message, test.block, err)
+		}
+		if status.Code(rangeErr.Err) != codes.NotFound {
+			t.Fatalf("Expected the access node error to be kept, got: %v", rangeErr.Err)
+		}
+	}
+
+	// without the latest height a missing height can't be placed
+	client.headerErr = status.Error(codes.Unavailable, "unavailable")
+	_, err = ExecuteScript(context.Background(), client, AtHeight(25), req)
+	if !errors.Is(err, ErrSporkRange) || !strings.Contains(err.Error(), "couldn't be read") || strings.Contains(err.Error(), "before the first block") {
+		t.Fatalf("Expected a spork range error of unknown position, got: %v", err)
+	}
+}
@@ -0,0 +1,193 @@
+package arenatoken
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go-sdk"
+)
+
+// LinkReport describes the link stored at a public capability path
+type LinkReport struct {
+	// Linked reports whether a link is stored at the path
+	Linked bool
+	// Target is the path the link points to, empty when not linked
+	Target string
+	// BorrowsAs lists the reference types the capability borrows as, out of
+	// the vault and fungible token interface types the script probes
+	BorrowsAs []string
+	// Stored is the type identifier of the resource stored at the target, empty
+	// when the capability doesn't borrow a resource
+	Stored string
+}
+
+// borrowsAs reports whether the capability borrows as typ
+func (l *LinkReport) borrowsAs(typ string) bool {
+	for _, t := range l.BorrowsAs {
+		if t == typ {
+			return true
+		}
+	}
+	return false
+}
+
+// AccountReport describes how far an account is set up to hold tokens, for
+// diagnosing accounts whose vault is stored but whose links are missing or
+// broken. Scripts can't read account storage, so the vault is only seen
+// through the links that target it, as is the vault of VaultStatus.
+type AccountReport struct {
+	// Vault reports whether a token vault is stored at the vault storage path,
+	// nil when no link targets the path
+	Vault *bool
+	// Occupied is the type identifier of a resource other than a token vault
+	// stored at the vault storage path, empty when none is seen
+	Occupied string
+	// Receiver describes the link at the receiver public path
+	Receiver LinkReport
+	// Balance describes the link at the balance public path
+	Balance LinkReport
+	// Problems lists what keeps the account from being set up, empty when it is
+	Problems []string
+}
+
+// Ready reports whether no problems were found
+func (a *AccountReport) Ready() bool {
+	return len(a.Problems) == 0
+}
+
+// Repairable reports whether the RepairAccount transaction fixes the problems
+// found. It saves a vault when none is stored and relinks both capabilities,
+// but fails when another resource is stored at the vault storage path. Such a
+// resource is only seen through a link that targets the path.
+func (a *AccountReport) Repairable() bool {
+	return !a.Ready() && a.Occupied == ""
+}
+
+// DecodeAccountReport returns the report of an InspectAccount script result,
+// listing the problems found against the paths and contract name of r
+func (r *ArenaToken) DecodeAccountReport(v cadence.Value) (*AccountReport, error) {
+	fields, err := structValues(v, "AccountReport")
+	if err != nil {
+		return nil, err
+	}
+
+	vaultType, ok := fields["vaultType"].(cadence.String)
+	if !ok {
+		return nil, fmt.Errorf("%w: AccountReport.vaultType is %T", ErrResultType, fields["vaultType"])
+	}
+
+	report := &AccountReport{}
+	if report.Receiver, err = decodeLinkReport(fields["receiver"]); err != nil {
+		return nil, err
+	}
+	if report.Balance, err = decodeLinkReport(fields["balance"]); err != nil {
+		return nil, err
+	}
+	r.diagnose(report, string(vaultType))
+	return report, nil
+}
+
+// InspectAccount returns the readiness report of account, without failing for
+// accounts that aren't set up
+func (r *ArenaToken) InspectAccount(ctx context.Context, client ScriptExecutor, account flow.Address) (*AccountReport, error) {
+	req, err := r.BuildInspectAccount(account)
+	if err != nil {
+		return nil, err
+	}
+	v, err := client.ExecuteScriptAtLatestBlock(ctx, req.Script, req.Arguments)
+	if err != nil {
+		return nil, fmt.Errorf("executing %s: %w", req.Template, err)
+	}
+	return r.DecodeAccountReport(v)
+}
+
+// diagnose infers what is stored at the vault path from the links that target
+// it, vaults being of vaultType, and lists the problems of report
+func (r *ArenaToken) diagnose(report *AccountReport, vaultType string) {
+	vaultPath := r.values.Paths.VaultStorage
+	for _, link := range []*LinkReport{&report.Receiver, &report.Balance} {
+		if link.Target != vaultPath {
+			continue
+		}
+		stored := link.Stored == vaultType || (report.Vault != nil && *report.Vault)
+		report.Vault = &stored
+		if link.Stored != "" && link.Stored != vaultType {
+			report.Occupied = link.Stored
+		}
+	}
+
+	report.Problems = append(report.Problems, r.linkProblems("ReceiverPublicPath", &report.Receiver, "FungibleToken.Receiver")...)
+	report.Problems = append(report.Problems, r.linkProblems("BalancePublicPath", &report.Balance, "FungibleToken.Balance")...)
+	switch {
+	case report.Occupied != "":
+		report.Problems = append(report.Problems, fmt.Sprintf("VaultStoragePath stores a %s rather than a token vault", report.Occupied))
+	case report.Vault != nil && !*report.Vault:
+		report.Problems = append(report.Problems, "no token vault is stored at VaultStoragePath")
+	}
+}
+
+// linkProblems lists the problems of the link at the public path name, which
+// should borrow as the vault restricted to iface
+func (r *ArenaToken) linkProblems(name string, link *LinkReport, iface string) []string {
+	expected := fmt.Sprintf("&%s.Vault{%s}", r.values.Contract, iface)
+	switch {
+	case !link.Linked:
+		return []string{name + " is not linked"}
+	case link.borrowsAs(expected):
+		return nil
+	case len(link.BorrowsAs) > 0:
+		return []string{fmt.Sprintf("%s borrows as %s rather than %s", name, link.BorrowsAs[0], expected)}
+	case link.Target == r.values.Paths.VaultStorage:
+		return []string{fmt.Sprintf("%s links to VaultStoragePath but doesn't borrow as %s", name, expected)}
+	default:
+		return []string{fmt.Sprintf("%s links to %s rather than VaultStoragePath", name, link.Target)}
+	}
+}
+
+// decodeLinkReport returns the report of a LinkReport struct
+func decodeLinkReport(v cadence.Value) (LinkReport, error) {
+	fields, err := structValues(v, "LinkReport")
+	if err != nil {
+		return LinkReport{}, err
+	}
+
+	var link LinkReport
+	target, ok := fields["target"].(cadence.Optional)
+	if !ok {
+		return LinkReport{}, fmt.Errorf("%w: LinkReport.target is %T", ErrResultType, fields["target"])
+	}
+	switch path := target.Value.(type) {
+	case nil:
+	case cadence.Path:
+		link.Linked = true
+		link.Target = path.String()
+	default:
+		return LinkReport{}, fmt.Errorf("%w: LinkReport.target is %T", ErrResultType, path)
+	}
+
+	types, ok := fields["borrowsAs"].(cadence.Array)
+	if !ok {
+		return LinkReport{}, fmt.Errorf("%w: LinkReport.borrowsAs is %T", ErrResultType, fields["borrowsAs"])
+	}
+	for _, t := range types.Values {
+		s, ok := t.(cadence.String)
+		if !ok {
+			return LinkReport{}, fmt.Errorf("%w: LinkReport.borrowsAs element is %T", ErrResultType, t)
+		}
+		link.BorrowsAs = append(link.BorrowsAs, string(s))
+	}
+
+	stored, ok := fields["stored"].(cadence.Optional)
+	if !ok {
+		return LinkReport{}, fmt.Errorf("%w: LinkReport.stored is %T", ErrResultType, fields["stored"])
+	}
+	switch typ := stored.Value.(type) {
+	case nil:
+	case cadence.String:
+		link.Stored = string(typ)
+	default:
+		return LinkReport{}, fmt.Errorf("%w: LinkReport.stored is %T", ErrResultType, typ)
+	}
+	return link, nil
+}
@@ -0,0 +1,112 @@
+package arenatoken
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/onflow/cadence"
+)
+
+// testVaultType is the type identifier of vaults in InspectAccount results
+const testVaultType = "A.0996b5100d5c8ad6.ArenaToken.Vault"
+
+// testLink is a link of an InspectAccount result, stored being the type of the
+// resource the link borrows
+type testLink struct {
+	target string
+	types  []string
+	stored string
+}
+
+// accountReport returns an AccountReport script result with the receiver and
+// balance links, whose target is empty when not linked
+func accountReport(receiver, balance testLink) cadence.Value {
+	linkType := &cadence.StructType{
+		QualifiedIdentifier: "LinkReport",
+		Fields: []cadence.Field{
+			{Identifier: "target", Type: cadence.OptionalType{Type: cadence.PathType{}}},
+			{Identifier: "borrowsAs", Type: cadence.VariableSizedArrayType{ElementType: cadence.StringType{}}},
+			{Identifier: "stored", Type: cadence.OptionalType{Type: cadence.StringType{}}},
+		},
+	}
+	link := func(l testLink) cadence.Value {
+		path := cadence.NewOptional(nil)
+		if l.target != "" {
+			parts := strings.SplitN(strings.TrimPrefix(l.target, "/"), "/", 2)
+			path = cadence.NewOptional(cadence.Path{Domain: parts[0], Identifier: parts[1]})
+		}
+		var values []cadence.Value
+		for _, t := range l.types {
+			values = append(values, cadence.NewString(t))
+		}
+		stored := cadence.NewOptional(nil)
+		if l.stored != "" {
+			stored = cadence.NewOptional(cadence.NewString(l.stored))
+		}
+		return cadence.NewStruct([]cadence.Value{path, cadence.NewArray(values), stored}).WithType(linkType)
+	}
+	return cadence.NewStruct([]cadence.Value{
+		link(receiver), link(balance), cadence.NewString(testVaultType),
+	}).WithType(&cadence.StructType{
+		QualifiedIdentifier: "AccountReport",
+		Fields: []cadence.Field{
+			{Identifier: "receiver", Type: linkType},
+			{Identifier: "balance", Type: linkType},
+			{Identifier: "vaultType", Type: cadence.StringType{}},
+		},
+	})
+}
+
+func TestInspectAccount(t *testing.T) {
+	r := New(benchArenaTokenAddr, benchFungibleTokenAddr)
+	vault := DefaultPaths().VaultStorage
+	receiver := []string{"&ArenaToken.Vault{FungibleToken.Receiver}", "&{FungibleToken.Receiver}"}
+	balance := []string{"&ArenaToken.Vault{FungibleToken.Balance}", "&{FungibleToken.Balance}"}
+
+	tests := []struct {
+		name       string
+		result     cadence.Value
+		vault      *bool
+		problems   int
+		repairable bool
+	}{
+		{"Ready", accountReport(testLink{vault, receiver, testVaultType}, testLink{vault, balance, testVaultType}), boolPtr(true), 0, false},
+		{"NotSetUp", accountReport(testLink{}, testLink{}), nil, 2, true},
+		{"MissingReceiver", accountReport(testLink{}, testLink{vault, balance, testVaultType}), boolPtr(true), 1, true},
+		{"WrongType", accountReport(testLink{vault, receiver, testVaultType}, testLink{vault, balance[1:], testVaultType}), boolPtr(true), 1, true},
+		{"WrongTarget", accountReport(testLink{vault, receiver, testVaultType}, testLink{"/storage/other", nil, ""}), boolPtr(true), 1, true},
+		{"NoVault", accountReport(testLink{vault, nil, ""}, testLink{vault, nil, ""}), boolPtr(false), 3, true},
+		{"Occupied", accountReport(testLink{vault, nil, "A.01cf0e2f2f715450.Other.Collection"}, testLink{}), boolPtr(false), 3, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			report, err := r.InspectAccount(context.Background(), &fakeExecutor{result: test.result}, benchFungibleTokenAddr)
+			if err != nil {
+				t.Fatalf("InspectAccount: %v", err)
+			}
+			if (report.Vault == nil) != (test.vault == nil) || (report.Vault != nil && *report.Vault != *test.vault) {
+				t.Fatalf("Expected vault %v, got: %v", test.vault, report.Vault)
+			}
+			if len(report.Problems) != test.problems || report.Ready() != (test.problems == 0) || report.Repairable() != test.repairable {
+				t.Fatalf("Expected %d problems, got: %q", test.problems, report.Problems)
+			}
+		})
+	}
+
+	// another resource at the vault path is named and keeps the account from repair
+	other := "A.01cf0e2f2f715450.Other.Collection"
+	report, err := r.DecodeAccountReport(accountReport(testLink{vault, nil, other}, testLink{}))
+	if err != nil || report.Occupied != other || !strings.Contains(strings.Join(report.Problems, "; "), other) {
+		t.Fatalf("Expected VaultStoragePath occupied by %s, got: %+v %v", other, report, err)
+	}
+
+	if _, err := r.InspectAccount(context.Background(), &fakeExecutor{result: cadence.NewBool(true)}, benchFungibleTokenAddr); !errors.Is(err, ErrResultType) {
+		t.Fatalf("Expected ErrResultType, got: %v", err)
+	}
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
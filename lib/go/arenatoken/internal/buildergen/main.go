@@ -0,0 +1,341 @@
+// Command buildergen generates an ArenaToken builder method for every transaction
+// and script template in the embedded cadence directory. Builder parameters are
+// derived from the transaction(...) or main(...) signature of each template.
+//
+// A template can customise its builder with a directive comment:
+//
+//	// arena:builder MintTokens gas=60 authorize
+//
+// The first field is the builder name, defaulting to the camel cased file name.
+// gas sets the gas limit of a transaction and authorize adds the prepare(...)
+// accounts as leading address parameters that are set as authorizers.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"io/fs"
+	"io/ioutil"
+	"log"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	arenacadence "github.com/arena/arena-cadence"
+	"github.com/onflow/cadence/runtime/ast"
+	"github.com/onflow/cadence/runtime/parser2"
+)
+
+var (
+	output = flag.String("o", "builders_gen.go", "output file")
+
+	directiveRe = regexp.MustCompile(`(?m)^//\s*arena:builder\b(.*)$`)
+)
+
+// placeholders are the template values and import function used to render a
+// template as parseable cadence. Only the signature of the result is used.
+var (
+	placeholderFuncs = template.FuncMap{
+		"import": func(c string) string { return fmt.Sprintf("import %s from 0x01", c) },
+	}
+	placeholderValues = map[string]interface{}{
+		"Contract": "ArenaToken",
+		"Paths": map[string]string{
+			"VaultStorage":        "/storage/vault",
+			"ReceiverPublic":      "/public/receiver",
+			"BalancePublic":       "/public/balance",
+			"AdminStorage":        "/storage/admin",
+			"AdminClaimerStorage": "/storage/adminClaimer",
+			"AdminClaimerPrivate": "/private/adminClaimer",
+			"AdminOfferStorage":   "/storage/adminOffer",
+		},
+	}
+)
+
+// builder is a generated builder method
+type builder struct {
+	Name        string
+	Path        string
+	Script      bool
+	GasLimit    string
+	Authorize   bool
+	Authorizers []string
+	// AuthParams are the go names of the authorizer parameters
+	AuthParams []string
+	Params     []param
+	Doc        []string
+}
+
+// Signature returns the go parameter list of the builder
+func (b builder) Signature() string {
+	var fields []string
+	for _, name := range b.AuthParams {
+		fields = append(fields, name+" flow.Address")
+	}
+	for _, p := range b.Params {
+		fields = append(fields, p.Name+" "+p.GoType)
+	}
+	return strings.Join(fields, ", ")
+}
+
+// param is a builder parameter and the expression converting it to a cadence.Value
+type param struct {
+	Name   string
+	GoType string
+	Arg    string
+}
+
+func main() {
+	flag.Parse()
+
+	var builders []builder
+	for _, dir := range []string{"cadence/transactions", "cadence/scripts"} {
+		err := fs.WalkDir(arenacadence.Cadence, dir, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() || path.Ext(p) != ".cdc" {
+				return nil
+			}
+			b, err := parseBuilder(p, dir == "cadence/scripts")
+			if err != nil {
+				return fmt.Errorf("%s: %v", p, err)
+			}
+			builders = append(builders, b)
+			return nil
+		})
+		if err != nil {
+			log.Fatalf("Walking %s: %v", dir, err)
+		}
+	}
+
+	sort.Slice(builders, func(i, j int) bool { return builders[i].Name < builders[j].Name })
+	for i := 1; i < len(builders); i++ {
+		if builders[i].Name == builders[i-1].Name {
+			log.Fatalf("Duplicate builder %s: %s and %s", builders[i].Name, builders[i-1].Path, builders[i].Path)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := fileTemplate.Execute(&buf, builders); err != nil {
+		log.Fatalf("Executing template: %v", err)
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatalf("Formatting generated source: %v\n%s", err, buf.Bytes())
+	}
+	if err := ioutil.WriteFile(*output, src, 0644); err != nil {
+		log.Fatalf("Writing %s: %v", *output, err)
+	}
+}
+
+// parseBuilder derives the builder for the template at p from its directive and signature
+func parseBuilder(p string, script bool) (builder, error) {
+	text, err := arenacadence.Cadence.ReadFile(p)
+	if err != nil {
+		return builder{}, err
+	}
+
+	b := builder{
+		Name:     camelCase(strings.TrimSuffix(path.Base(p), ".cdc")),
+		Path:     p,
+		Script:   script,
+		GasLimit: "defaultGasLimit",
+	}
+	if m := directiveRe.FindSubmatch(text); m != nil {
+		for i, field := range strings.Fields(string(m[1])) {
+			switch {
+			case field == "authorize":
+				b.Authorize = true
+			case strings.HasPrefix(field, "gas="):
+				limit := strings.TrimPrefix(field, "gas=")
+				if _, err := strconv.ParseUint(limit, 10, 64); err != nil {
+					return builder{}, fmt.Errorf("invalid gas limit %q", limit)
+				}
+				b.GasLimit = limit
+			case i == 0:
+				b.Name = field
+			default:
+				return builder{}, fmt.Errorf("unknown directive field %q", field)
+			}
+		}
+	}
+
+	tpl, err := template.New(p).Funcs(placeholderFuncs).Parse(string(text))
+	if err != nil {
+		return builder{}, err
+	}
+	var code bytes.Buffer
+	if err := tpl.Execute(&code, placeholderValues); err != nil {
+		return builder{}, err
+	}
+	program, err := parser2.ParseProgram(code.String())
+	if err != nil {
+		return builder{}, err
+	}
+
+	params := &ast.ParameterList{}
+	var doc string
+	if txs := program.TransactionDeclarations(); len(txs) > 0 && !script {
+		if txs[0].ParameterList != nil {
+			params = txs[0].ParameterList
+		}
+		doc = txs[0].DocString
+		if txs[0].Prepare != nil {
+			for _, p := range txs[0].Prepare.FunctionDeclaration.ParameterList.Parameters {
+				b.Authorizers = append(b.Authorizers, p.Identifier.Identifier)
+			}
+		}
+	} else {
+		var found bool
+		for _, fn := range program.FunctionDeclarations() {
+			if fn.Identifier.Identifier == "main" {
+				params = fn.ParameterList
+				doc = fn.DocString
+				found = true
+			}
+		}
+		if !found {
+			return builder{}, fmt.Errorf("no transaction or main function declared")
+		}
+	}
+	if b.Authorize && b.Script {
+		return builder{}, fmt.Errorf("scripts have no authorizers")
+	}
+
+	used := map[string]bool{}
+	if b.Authorize {
+		for _, name := range b.Authorizers {
+			b.AuthParams = append(b.AuthParams, goName(name))
+			used[goName(name)] = true
+		}
+	}
+	for _, p := range params.Parameters {
+		name := goName(p.Identifier.Identifier)
+		if used[name] {
+			name += "Arg"
+		}
+		used[name] = true
+		goType, arg := goType(p.TypeAnnotation.Type)
+		b.Params = append(b.Params, param{Name: name, GoType: goType, Arg: fmt.Sprintf(arg, name)})
+	}
+
+	for _, line := range strings.Split(strings.TrimSpace(doc), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			b.Doc = append(b.Doc, line)
+		}
+	}
+
+	return b, nil
+}
+
+// goType returns the go type of a builder parameter for the cadence type and a
+// format string converting a value of that type to a cadence.Value
+func goType(t ast.Type) (string, string) {
+	switch t := t.(type) {
+	case *ast.NominalType:
+		switch name := t.String(); name {
+		case "Address":
+			return "flow.Address", "cadenceAddress(%s)"
+		case "String":
+			return "string", "cadence.NewString(%s)"
+		case "Bool":
+			return "bool", "cadence.NewBool(%s)"
+		case "Int", "Int8", "Int16", "Int32", "Int64", "Int128", "Int256",
+			"UInt", "UInt8", "UInt16", "UInt32", "UInt64", "UInt128", "UInt256",
+			"Word8", "Word16", "Word32", "Word64", "Fix64", "UFix64":
+			return "cadence." + name, "%s"
+		}
+	case *ast.VariableSizedType:
+		switch t.Type.String() {
+		case "Address":
+			return "[]flow.Address", "cadenceAddresses(%s)"
+		case "UFix64":
+			return "[]cadence.UFix64", "cadenceUFix64s(%s)"
+		}
+	case *ast.DictionaryType:
+		if t.KeyType.String() == "Address" && t.ValueType.String() == "UFix64" {
+			return "map[flow.Address]cadence.UFix64", "cadenceAmounts(%s)"
+		}
+	}
+	return "cadence.Value", "%s"
+}
+
+var goKeywords = map[string]bool{
+	"break": true, "case": true, "chan": true, "const": true, "continue": true,
+	"default": true, "defer": true, "else": true, "fallthrough": true, "for": true,
+	"func": true, "go": true, "goto": true, "if": true, "import": true,
+	"interface": true, "map": true, "package": true, "range": true, "return": true,
+	"select": true, "struct": true, "switch": true, "type": true, "var": true,
+}
+
+// goName returns a parameter name that doesn't shadow keywords or the receiver
+func goName(name string) string {
+	if goKeywords[name] || name == "r" || name == "req" || name == "err" {
+		return name + "Arg"
+	}
+	return name
+}
+
+func camelCase(s string) string {
+	var out strings.Builder
+	for _, part := range strings.FieldsFunc(s, func(r rune) bool { return r == '_' || r == '-' }) {
+		out.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return out.String()
+}
+
+var fileTemplate = template.Must(template.New("builders").Funcs(template.FuncMap{
+	"join": strings.Join,
+}).Parse(`// Code generated by buildergen from the embedded cadence directory. DO NOT EDIT.
+
+package arenatoken
+
+import (
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go-sdk"
+)
+
+// ensure imports are used by every generated file
+var (
+	_ cadence.Value
+	_ flow.Address
+)
+{{ range . }}
+{{- if .Script }}
+// Build{{ .Name }} returns a script rendered from {{ .Path }}
+{{- else }}
+// Build{{ .Name }} returns an unsigned transaction rendered from {{ .Path }}
+{{- if .Authorize }}
+// with {{ join .Authorizers ", " }} as its authorizers, in that order
+{{- end }}
+{{- end }}
+{{- if .Doc }}
+//
+{{- range .Doc }}
+// {{ . }}
+{{- end }}
+{{- end }}
+func (r *ArenaToken) Build{{ .Name }}({{ .Signature }}) ({{ if .Script }}*ScriptRequest{{ else }}*TransactionRequest{{ end }}, error) {
+{{- if .Script }}
+	return r.scriptRequest(lookupTemplate("{{ .Path }}"){{ range .Params }}, {{ .Arg }}{{ end }})
+{{- else if .Authorize }}
+	req, err := r.transaction(lookupTemplate("{{ .Path }}"), {{ .GasLimit }}{{ range .Params }}, {{ .Arg }}{{ end }})
+	if err != nil {
+		return nil, err
+	}
+	if err := req.Authorize({{ join .AuthParams ", " }}); err != nil {
+		return nil, err
+	}
+	return req, nil
+{{- else }}
+	return r.transaction(lookupTemplate("{{ .Path }}"), {{ .GasLimit }}{{ range .Params }}, {{ .Arg }}{{ end }})
+{{- end }}
+}
+{{ end -}}
+`))
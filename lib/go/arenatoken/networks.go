@@ -0,0 +1,138 @@
+package arenatoken
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/onflow/flow-go-sdk"
+)
+
+var (
+	// ErrUnknownNetwork is returned for networks with no registered contracts
+	ErrUnknownNetwork = errors.New("unknown network")
+	// ErrUnknownContract is returned when a network has no address for a contract
+	ErrUnknownContract = errors.New("unknown contract")
+)
+
+// registry holds the well known contract addresses of each network keyed by
+// chain ID and contract name
+var registry = struct {
+	sync.RWMutex
+	networks map[flow.ChainID]map[string]flow.Address
+}{
+	networks: map[flow.ChainID]map[string]flow.Address{
+		flow.Mainnet: {
+			"FungibleToken": flow.HexToAddress("0xf233dcee88fe0abe"),
+			"FlowToken":     flow.HexToAddress("0x1654653399040a61"),
+		},
+		flow.Testnet: {
+			"FungibleToken": flow.HexToAddress("0x9a0766d93b6608b7"),
+			"FlowToken":     flow.HexToAddress("0x7e60df042a9c0868"),
+			"ArenaToken":    flow.HexToAddress("0x0996b5100d5c8ad6"),
+		},
+		flow.Emulator: {
+			"FungibleToken": flow.HexToAddress("0xee82856bf20e2aa6"),
+			"FlowToken":     flow.HexToAddress("0x0ae53cb6e3f42a79"),
+			// contracts are deployed to the service account during development
+			"ArenaToken": flow.HexToAddress("0xf8d6e0586b0a20c7"),
+		},
+	},
+}
+
+// Register sets the address of a contract on a network, adding the network if
+// it is not known yet. It is safe to call concurrently with ForNetwork.
+func Register(network flow.ChainID, contract string, addr flow.Address) {
+	registry.Lock()
+	defer registry.Unlock()
+
+	if registry.networks[network] == nil {
+		registry.networks[network] = make(map[string]flow.Address)
+	}
+	registry.networks[network][contract] = addr
+}
+
+// Lookup returns the registered address of a contract on a network
+func Lookup(network flow.ChainID, contract string) (flow.Address, error) {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	contracts, ok := registry.networks[network]
+	if !ok {
+		return flow.EmptyAddress, fmt.Errorf("%w: %s", ErrUnknownNetwork, network)
+	}
+	addr, ok := contracts[contract]
+	if !ok {
+		return flow.EmptyAddress, fmt.Errorf("%w: %s on %s", ErrUnknownContract, contract, network)
+	}
+	return addr, nil
+}
+
+// NetworkAddresses returns a copy of the registered contract addresses of a network
+func NetworkAddresses(network flow.ChainID) (map[string]flow.Address, error) {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	contracts, ok := registry.networks[network]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownNetwork, network)
+	}
+	out := make(map[string]flow.Address, len(contracts))
+	for name, addr := range contracts {
+		out[name] = addr
+	}
+	return out, nil
+}
+
+// ForNetwork returns a handle importing every contract registered for the
+// network, see FromAddresses. ArenaToken isn't deployed to mainnet yet, so the
+// registry holds no address for it there and ForNetwork(flow.Mainnet) returns
+// ErrUnknownContract until one is set with Register.
+func ForNetwork(network flow.ChainID, opts ...Option) (*ArenaToken, error) {
+	contracts, err := NetworkAddresses(network)
+	if err != nil {
+		return nil, err
+	}
+	r, err := FromAddresses(contracts, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", network, err)
+	}
+	return r, nil
+}
+
+// FromAddresses returns a handle importing every contract in contracts. The
+// token contract is looked up under the name set by WithContractName, ArenaToken
+// by default, and the other contracts, ArenaToken included when renamed, stay
+// importable under their own names. Imports set by options take precedence
+// over the provided addresses.
+func FromAddresses(contracts map[string]flow.Address, opts ...Option) (*ArenaToken, error) {
+	ft, ok := contracts["FungibleToken"]
+	if !ok {
+		return nil, fmt.Errorf("%w: FungibleToken", ErrUnknownContract)
+	}
+
+	// apply the options once to resolve the configured contract name, which
+	// may also be imported explicitly
+	probe := New(flow.EmptyAddress, ft, opts...)
+	if probe.err != nil {
+		return nil, probe.err
+	}
+	name := probe.values.Contract
+	addr, ok := contracts[name]
+	if imported := probe.contracts[name]; imported != flow.EmptyAddress {
+		addr, ok = imported, true
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownContract, name)
+	}
+
+	// add the provided addresses once the options have renamed the token
+	// contract, so that its default name isn't moved to the new one
+	r := New(addr, ft, opts...)
+	for name, addr := range contracts {
+		if _, ok := r.contracts[name]; !ok {
+			r.contracts[name] = addr
+		}
+	}
+	return r, nil
+}
@@ -0,0 +1,67 @@
+package arenatoken
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/onflow/flow-go-sdk"
+)
+
+func TestForNetwork(t *testing.T) {
+	r, err := ForNetwork(flow.Testnet)
+	if err != nil {
+		t.Fatalf("ForNetwork: %v", err)
+	}
+	req, err := r.BuildSetupAccount()
+	if err != nil {
+		t.Fatalf("BuildSetupAccount: %v", err)
+	}
+	for _, imp := range []string{"import FungibleToken from 0x9a0766d93b6608b7", "import ArenaToken from 0x0996b5100d5c8ad6"} {
+		if !strings.Contains(string(req.Transaction.Script), imp) {
+			t.Fatalf("Expected %q in script:\n%s", imp, req.Transaction.Script)
+		}
+	}
+
+	if _, err := ForNetwork("flow-unknown"); !errors.Is(err, ErrUnknownNetwork) {
+		t.Fatalf("Expected ErrUnknownNetwork, got: %v", err)
+	}
+	if _, err := ForNetwork(flow.Mainnet); !errors.Is(err, ErrUnknownContract) {
+		t.Fatalf("Expected ErrUnknownContract, got: %v", err)
+	}
+	if _, err := ForNetwork(flow.Testnet, WithContractName("ArenaGold")); !errors.Is(err, ErrUnknownContract) {
+		t.Fatalf("Expected ErrUnknownContract, got: %v", err)
+	}
+}
+
+func TestRegister(t *testing.T) {
+	const network flow.ChainID = "arena-register-test"
+	ft := flow.HexToAddress("0x01")
+	gold := flow.HexToAddress("0x02")
+	token := flow.HexToAddress("0x03")
+	Register(network, "FungibleToken", ft)
+	Register(network, "ArenaGold", gold)
+	Register(network, "ArenaToken", token)
+
+	// renaming the token contract keeps the registered ArenaToken import
+	r, err := ForNetwork(network, WithContractName("ArenaGold"))
+	if err != nil {
+		t.Fatalf("ForNetwork: %v", err)
+	}
+	if r.contracts["ArenaGold"] != gold || r.contracts["FungibleToken"] != ft || r.contracts["ArenaToken"] != token {
+		t.Fatalf("Unexpected contracts: %v", r.contracts)
+	}
+	if r.ContractAddress() != gold {
+		t.Fatalf("Expected token contract at %s, got: %s", gold, r.ContractAddress())
+	}
+
+	// imports set by options take precedence
+	other := flow.HexToAddress("0x04")
+	r, err = ForNetwork(network, WithImport("ArenaToken", other))
+	if err != nil || r.ContractAddress() != other {
+		t.Fatalf("Expected ArenaToken imported from %s, got: %v %v", other, r.contracts, err)
+	}
+	if addr, err := Lookup(network, "ArenaGold"); err != nil || addr != gold {
+		t.Fatalf("Lookup: %v, %v", addr, err)
+	}
+}
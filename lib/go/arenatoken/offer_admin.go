@@ -0,0 +1,18 @@
+package arenatoken
+
+import (
+	"github.com/onflow/flow-go-sdk"
+)
+
+// OfferAdministrator returns an unsigned transaction that escrows the singular Admin
+// resource in the storage of the contract account, at the AdminOfferStorage path,
+// until newAdmin claims it with ClaimAdministrator. Only the current admin
+// authorizes the transaction. It panics if the transaction
+// cannot be built, see BuildOfferAdministrator.
+func (r *ArenaToken) OfferAdministrator(currentAdmin, newAdmin flow.Address) *flow.Transaction {
+	req, err := r.BuildOfferAdministrator(currentAdmin, newAdmin)
+	if err != nil {
+		panic(err)
+	}
+	return req.Transaction
+}
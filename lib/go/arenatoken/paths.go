@@ -0,0 +1,101 @@
+package arenatoken
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+// ErrInvalidOption is wrapped by the error returned from builders of a handle
+// created with an invalid option
+var ErrInvalidOption = errors.New("invalid option")
+
+// DefaultContractName is the name the token contract is deployed with unless
+// overridden by WithContractName
+const DefaultContractName = "ArenaToken"
+
+var (
+	identifierRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	pathRe       = regexp.MustCompile(`^/(storage|public|private)/[A-Za-z_][A-Za-z0-9_]*$`)
+)
+
+// Paths are the storage and capability paths set by the token contract when it
+// is deployed. Each is a cadence path literal such as /storage/arenaTokenVault.
+type Paths struct {
+	VaultStorage        string
+	ReceiverPublic      string
+	BalancePublic       string
+	AdminStorage        string
+	AdminClaimerStorage string
+	AdminClaimerPrivate string
+	AdminOfferStorage   string
+}
+
+// DefaultPaths returns the paths of the ArenaToken deployment
+func DefaultPaths() Paths {
+	return Paths{
+		VaultStorage:        "/storage/arenaTokenVault",
+		ReceiverPublic:      "/public/arenaTokenReceiver",
+		BalancePublic:       "/public/arenaTokenBalance",
+		AdminStorage:        "/storage/arenaTokenAdmin",
+		AdminClaimerStorage: "/storage/arenaTokenAdminClaimer",
+		AdminClaimerPrivate: "/private/arenaTokenAdminClaimer",
+		AdminOfferStorage:   "/storage/arenaTokenAdminOffer",
+	}
+}
+
+// withDefaults returns p with empty fields set to their default path
+func (p Paths) withDefaults() Paths {
+	defaults := DefaultPaths()
+	if p.VaultStorage == "" {
+		p.VaultStorage = defaults.VaultStorage
+	}
+	if p.ReceiverPublic == "" {
+		p.ReceiverPublic = defaults.ReceiverPublic
+	}
+	if p.BalancePublic == "" {
+		p.BalancePublic = defaults.BalancePublic
+	}
+	if p.AdminStorage == "" {
+		p.AdminStorage = defaults.AdminStorage
+	}
+	if p.AdminClaimerStorage == "" {
+		p.AdminClaimerStorage = defaults.AdminClaimerStorage
+	}
+	if p.AdminClaimerPrivate == "" {
+		p.AdminClaimerPrivate = defaults.AdminClaimerPrivate
+	}
+	if p.AdminOfferStorage == "" {
+		p.AdminOfferStorage = defaults.AdminOfferStorage
+	}
+	return p
+}
+
+// validate checks that every path is a literal in the domain its field names
+func (p Paths) validate() error {
+	for _, path := range []struct{ name, domain, value string }{
+		{"VaultStorage", "storage", p.VaultStorage},
+		{"ReceiverPublic", "public", p.ReceiverPublic},
+		{"BalancePublic", "public", p.BalancePublic},
+		{"AdminStorage", "storage", p.AdminStorage},
+		{"AdminClaimerStorage", "storage", p.AdminClaimerStorage},
+		{"AdminClaimerPrivate", "private", p.AdminClaimerPrivate},
+		{"AdminOfferStorage", "storage", p.AdminOfferStorage},
+	} {
+		m := pathRe.FindStringSubmatch(path.value)
+		if m == nil || m[1] != path.domain {
+			return fmt.Errorf("%w: %s path %q is not a /%s/ path", ErrInvalidOption, path.name, path.value, path.domain)
+		}
+	}
+	return nil
+}
+
+// templateValues are the values every template is rendered with
+type templateValues struct {
+	// Contract is the name of the token contract
+	Contract string
+	Paths    Paths
+}
+
+// defaultValues are the values of a handle created without options
+var defaultValues = templateValues{Contract: DefaultContractName, Paths: DefaultPaths()}
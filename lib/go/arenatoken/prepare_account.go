@@ -5,10 +5,25 @@ import (
 )
 
 // SetupAccount returns an unsigned transaction that prepares a user account
-// for sending and receiving ArenaTokens.
+// for sending and receiving ArenaTokens. It panics if the transaction cannot
+// be built, see BuildSetupAccount.
 func (r *ArenaToken) SetupAccount() *flow.Transaction {
-	tx := render(setupAccountTemplate, nil, r.contracts)
-	return flow.NewTransaction().
-		SetScript([]byte(tx)).
-		SetGasLimit(100)
+	req, err := r.BuildSetupAccount()
+	if err != nil {
+		panic(err)
+	}
+	return req.Transaction
+}
+
+// RepairAccount returns an unsigned transaction that relinks the receiver and
+// balance capabilities of an account, for accounts SetupAccount skips because
+// of the stored vault. An empty vault is saved first when none is stored, and a
+// stored vault is left untouched. It panics if the transaction cannot be built,
+// see BuildRepairAccount.
+func (r *ArenaToken) RepairAccount() *flow.Transaction {
+	req, err := r.BuildRepairAccount()
+	if err != nil {
+		panic(err)
+	}
+	return req.Transaction
 }
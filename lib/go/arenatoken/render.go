@@ -2,37 +2,119 @@ package arenatoken
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
-	"log"
 	"text/template"
 
 	"github.com/onflow/flow-go-sdk"
 )
 
-// render executes the provided go template file resolving the provided contract imports
-func render(tpl string, obj interface{}, contracts map[string]flow.Address) string {
+// ErrUnresolvedImport is wrapped by a RenderError when a template imports a
+// contract that has no known address
+var ErrUnresolvedImport = errors.New("unresolved contract import")
 
-	// capture contracts mapping via closure
-	importExpander := func(c string) string {
+// RenderStage identifies the step of rendering a template that failed
+type RenderStage int
+
+const (
+	// StageParse indicates the template text could not be parsed
+	StageParse RenderStage = iota
+	// StageExecute indicates the parsed template failed to execute
+	StageExecute
+	// StageImport indicates the template imports an unknown contract
+	StageImport
+)
+
+func (s RenderStage) String() string {
+	switch s {
+	case StageParse:
+		return "parse"
+	case StageExecute:
+		return "execute"
+	case StageImport:
+		return "import"
+	}
+	return fmt.Sprintf("RenderStage(%d)", int(s))
+}
+
+// RenderError is returned when a cadence template cannot be rendered
+type RenderError struct {
+	// Template is the path of the template within the embedded fs
+	Template string
+	Stage    RenderStage
+	// Contract is the name of the unresolved import when Stage is StageImport
+	Contract string
+	Err      error
+}
+
+func (e *RenderError) Error() string {
+	if e.Stage == StageImport {
+		return fmt.Sprintf("render %s: %v: %s", e.Template, e.Err, e.Contract)
+	}
+	return fmt.Sprintf("render %s: %s: %v", e.Template, e.Stage, e.Err)
+}
+
+func (e *RenderError) Unwrap() error {
+	return e.Err
+}
+
+// render executes the provided pre-parsed template resolving the provided contract imports
+func render(t *cadenceTemplate, obj interface{}, contracts map[string]flow.Address) (string, error) {
+	if t.err != nil {
+		return "", &RenderError{Template: t.path, Stage: StageParse, Err: t.err}
+	}
+
+	// capture contracts mapping via closure, remembering the first import that
+	// could not be resolved so it can be reported to the caller
+	var unresolved string
+	importExpander := func(c string) (string, error) {
 		if _, ok := contracts[c]; !ok {
-			return fmt.Sprintf("INVALID_IMPORT: %s", c)
+			unresolved = c
+			return "", ErrUnresolvedImport
 		}
-		return fmt.Sprintf("import %s from 0x%s", c, contracts[c])
+		return fmt.Sprintf("import %s from 0x%s", c, contracts[c]), nil
 	}
 	funcMap := template.FuncMap{
 		"import": importExpander,
 	}
 
-	// Create a template, add the function map, and parse the text.
-	tmpl := template.Must(template.New("titleTest").Funcs(funcMap).Parse(tpl))
+	// Clone so the import function can be bound without racing other renders
+	tmpl, err := t.tpl.Clone()
+	if err != nil {
+		return "", &RenderError{Template: t.path, Stage: StageExecute, Err: err}
+	}
 
-	// Run the template to verify the output.
 	var buf bytes.Buffer
-	err := tmpl.Execute(&buf, obj)
-	if err != nil {
-		log.Fatalf("execution: %s", err)
+	if err := tmpl.Funcs(funcMap).Execute(&buf, obj); err != nil {
+		if unresolved != "" {
+			return "", &RenderError{Template: t.path, Stage: StageImport, Contract: unresolved, Err: ErrUnresolvedImport}
+		}
+		return "", &RenderError{Template: t.path, Stage: StageExecute, Err: err}
+	}
+
+	return buf.String(), nil
+}
+
+// script returns the rendered source of the provided template. Renders are
+// memoized since the contract addresses and values of a handle never change, each call
+// returns a fresh copy so callers are free to modify it.
+func (r *ArenaToken) script(t *cadenceTemplate) ([]byte, error) {
+	if r.err != nil {
+		return nil, r.err
 	}
 
-	return buf.String()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if s, ok := r.scripts[t.path]; ok {
+		return []byte(s), nil
+	}
+
+	s, err := render(t, r.values, r.contracts)
+	if err != nil {
+		return nil, err
+	}
+	r.scripts[t.path] = s
 
+	return []byte(s), nil
 }
@@ -0,0 +1,81 @@
+package arenatoken
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"text/template"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go-sdk"
+)
+
+const transferPath = "cadence/transactions/arenaToken/transfer.cdc"
+
+var (
+	benchArenaTokenAddr    = flow.HexToAddress("0x0996b5100d5c8ad6")
+	benchFungibleTokenAddr = flow.HexToAddress("0x9a0766d93b6608b7")
+)
+
+// BenchmarkRenderUncached parses the template text on every call which is
+// how builders rendered scripts before templates were parsed at init.
+func BenchmarkRenderUncached(b *testing.B) {
+	contracts := New(benchArenaTokenAddr, benchFungibleTokenAddr).contracts
+	text := readTemplate(transferPath)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		funcMap := template.FuncMap{
+			"import": func(c string) string {
+				return fmt.Sprintf("import %s from 0x%s", c, contracts[c])
+			},
+		}
+		tmpl := template.Must(template.New(transferPath).Funcs(funcMap).Parse(text))
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, defaultValues); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkRender renders a template parsed at init without memoization
+func BenchmarkRender(b *testing.B) {
+	contracts := New(benchArenaTokenAddr, benchFungibleTokenAddr).contracts
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := render(lookupTemplate(transferPath), defaultValues, contracts); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkTransfer(b *testing.B) {
+	r := New(benchArenaTokenAddr, benchFungibleTokenAddr)
+	amount, _ := cadence.NewUFix64("100.0")
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := r.BuildTransfer(benchFungibleTokenAddr, amount); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkMintTokens(b *testing.B) {
+	r := New(benchArenaTokenAddr, benchFungibleTokenAddr)
+	amount, _ := cadence.NewUFix64("100.0")
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := r.BuildMintTokens(benchFungibleTokenAddr, amount); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkBalance(b *testing.B) {
+	r := New(benchArenaTokenAddr, benchFungibleTokenAddr)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := r.BuildBalance(benchFungibleTokenAddr); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
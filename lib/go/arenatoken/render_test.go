@@ -0,0 +1,71 @@
+package arenatoken
+
+import (
+	"errors"
+	"testing"
+	"text/template"
+
+	"github.com/onflow/cadence"
+)
+
+// testTemplate parses text as the template at path, as parseTemplate does for
+// embedded files
+func testTemplate(path, text string) *cadenceTemplate {
+	funcMap := template.FuncMap{
+		"import": func(string) (string, error) { return "", ErrUnresolvedImport },
+	}
+	tpl, err := template.New(path).Funcs(funcMap).Parse(text)
+	return &cadenceTemplate{path: path, tpl: tpl, err: err}
+}
+
+func TestRender(t *testing.T) {
+	r := New(benchArenaTokenAddr, benchFungibleTokenAddr)
+
+	tests := []struct {
+		name     string
+		template *cadenceTemplate
+		stage    RenderStage
+		contract string
+		is       error
+	}{
+		{"Import", testTemplate("import.cdc", `{{ import "X" }}`), StageImport, "X", ErrUnresolvedImport},
+		{"Parse", testTemplate("parse.cdc", `{{ import "X" `), StageParse, "", nil},
+		{"Execute", testTemplate("execute.cdc", `{{ .Missing }}`), StageExecute, "", nil},
+		{"Missing", lookupTemplate("cadence/missing.cdc"), StageParse, "", nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := r.scriptRequest(test.template)
+			var renderErr *RenderError
+			if !errors.As(err, &renderErr) {
+				t.Fatalf("Expected RenderError, got: %v", err)
+			}
+			if renderErr.Template != test.template.path || renderErr.Stage != test.stage || renderErr.Contract != test.contract {
+				t.Fatalf("Expected %s error for %q, got: %+v", test.stage, test.contract, renderErr)
+			}
+			if test.is != nil && !errors.Is(err, test.is) {
+				t.Fatalf("Expected %v, got: %v", test.is, err)
+			}
+			if _, err := r.transaction(test.template, defaultGasLimit); !errors.As(err, &renderErr) {
+				t.Fatalf("Expected RenderError from transaction, got: %v", err)
+			}
+		})
+	}
+
+	// builders of a handle missing an import return the error rather than exit
+	r = New(benchArenaTokenAddr, benchFungibleTokenAddr)
+	delete(r.contracts, "FungibleToken")
+	amount, _ := cadence.NewUFix64("1.0")
+	for name, build := range map[string]func() error{
+		"BuildTransfer":     func() error { _, err := r.BuildTransfer(benchFungibleTokenAddr, amount); return err },
+		"BuildBalance":      func() error { _, err := r.BuildBalance(benchFungibleTokenAddr); return err },
+		"BuildSetupAccount": func() error { _, err := r.BuildSetupAccount(); return err },
+	} {
+		err := build()
+		var renderErr *RenderError
+		if !errors.Is(err, ErrUnresolvedImport) || !errors.As(err, &renderErr) ||
+			renderErr.Stage != StageImport || renderErr.Contract != "FungibleToken" {
+			t.Fatalf("%s: expected unresolved FungibleToken import, got: %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package arenatoken
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go-sdk"
+)
+
+// ErrAuthorizers is wrapped by errors returned when the authorizers assigned to
+// a transaction do not match the prepare block of its template
+var ErrAuthorizers = errors.New("authorizers do not match transaction")
+
+// TransactionRequest is an unsigned transaction along with the signer roles and
+// argument schema declared by the template it was built from
+type TransactionRequest struct {
+	Transaction *flow.Transaction
+	// Template is the path of the template within the embedded fs
+	Template string
+	Signature
+}
+
+// ValidateAuthorizers checks that the provided accounts can authorize the
+// transaction, i.e. there is exactly one per prepare parameter and, if the
+// builder already set the authorizers, they are the same and in the same order.
+func (r *TransactionRequest) ValidateAuthorizers(authorizers []flow.Address) error {
+	if len(authorizers) != len(r.Authorizers) {
+		return fmt.Errorf("%w: expected %d %v, got %d", ErrAuthorizers, len(r.Authorizers), r.Authorizers, len(authorizers))
+	}
+	for i, addr := range authorizers {
+		if addr == flow.EmptyAddress {
+			return fmt.Errorf("%w: empty address for %s", ErrAuthorizers, r.Authorizers[i])
+		}
+	}
+	if len(r.Transaction.Authorizers) == 0 {
+		return nil
+	}
+	for i, addr := range r.Transaction.Authorizers {
+		if addr != authorizers[i] {
+			return fmt.Errorf("%w: %s is %s, got %s", ErrAuthorizers, r.Authorizers[i], addr, authorizers[i])
+		}
+	}
+	return nil
+}
+
+// Authorize validates the provided accounts and adds them to the transaction as
+// authorizers if the builder has not already done so
+func (r *TransactionRequest) Authorize(authorizers ...flow.Address) error {
+	if err := r.ValidateAuthorizers(authorizers); err != nil {
+		return err
+	}
+	if len(r.Transaction.Authorizers) == 0 {
+		for _, addr := range authorizers {
+			r.Transaction.AddAuthorizer(addr)
+		}
+	}
+	return nil
+}
+
+// ScriptRequest is a script and its arguments along with the argument schema
+// declared by the template it was built from
+type ScriptRequest struct {
+	Script    []byte
+	Arguments []cadence.Value
+	// Template is the path of the template within the embedded fs
+	Template string
+	Signature
+}
+
+// defaultGasLimit is used by transactions whose template doesn't set a gas limit
+const defaultGasLimit = 100
+
+// transaction builds a request for the provided template and arguments
+func (r *ArenaToken) transaction(t *cadenceTemplate, gasLimit uint64, args ...cadence.Value) (*TransactionRequest, error) {
+	script, err := r.script(t)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.checkArguments(args); err != nil {
+		return nil, err
+	}
+
+	if r.gasLimit != 0 {
+		gasLimit = r.gasLimit
+	}
+
+	tx := flow.NewTransaction().
+		SetScript(script).
+		SetGasLimit(gasLimit)
+	for _, arg := range args {
+		if err := tx.AddArgument(arg); err != nil {
+			return nil, err
+		}
+	}
+
+	return &TransactionRequest{Transaction: tx, Template: t.path, Signature: t.sig}, nil
+}
+
+// scriptRequest builds a request for the provided script template and arguments
+func (r *ArenaToken) scriptRequest(t *cadenceTemplate, args ...cadence.Value) (*ScriptRequest, error) {
+	script, err := r.script(t)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.checkArguments(args); err != nil {
+		return nil, err
+	}
+
+	return &ScriptRequest{Script: script, Arguments: args, Template: t.path, Signature: t.sig}, nil
+}
+
+// cadenceAddress converts a flow address to its cadence argument form
+func cadenceAddress(addr flow.Address) cadence.Address {
+	var buf cadence.Address
+	copy(buf[:], addr.Bytes())
+	return buf
+}
+
+// cadenceAddresses converts flow addresses to a cadence [Address] argument
+func cadenceAddresses(addrs []flow.Address) cadence.Array {
+	values := make([]cadence.Value, len(addrs))
+	for i, addr := range addrs {
+		values[i] = cadenceAddress(addr)
+	}
+	return cadence.NewArray(values)
+}
+
+// cadenceUFix64s converts amounts to a cadence [UFix64] argument
+func cadenceUFix64s(amounts []cadence.UFix64) cadence.Array {
+	values := make([]cadence.Value, len(amounts))
+	for i, amount := range amounts {
+		values[i] = amount
+	}
+	return cadence.NewArray(values)
+}
+
+// cadenceAmounts converts amounts to a cadence {Address: UFix64} argument,
+// ordered by address so that the same amounts encode to the same arguments
+func cadenceAmounts(amounts map[flow.Address]cadence.UFix64) cadence.Dictionary {
+	addrs := make([]flow.Address, 0, len(amounts))
+	for addr := range amounts {
+		addrs = append(addrs, addr)
+	}
+	sort.Slice(addrs, func(i, j int) bool { return bytes.Compare(addrs[i][:], addrs[j][:]) < 0 })
+
+	pairs := make([]cadence.KeyValuePair, len(addrs))
+	for i, addr := range addrs {
+		pairs[i] = cadence.KeyValuePair{Key: cadenceAddress(addr), Value: amounts[addr]}
+	}
+	return cadence.NewDictionary(pairs)
+}
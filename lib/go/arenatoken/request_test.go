@@ -0,0 +1,271 @@
+package arenatoken
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go-sdk"
+)
+
+func TestTransactionRequestSignature(t *testing.T) {
+	r := New(benchArenaTokenAddr, benchFungibleTokenAddr)
+	amount, _ := cadence.NewUFix64("10.0")
+
+	req, err := r.BuildMintTokens(benchFungibleTokenAddr, amount)
+	if err != nil {
+		t.Fatalf("BuildMintTokens: %v", err)
+	}
+	if len(req.Authorizers) != 1 || req.Authorizers[0] != "signer" {
+		t.Fatalf("Expected authorizers [signer], got: %v", req.Authorizers)
+	}
+	expected := []Param{{Name: "recipient", Type: "Address"}, {Name: "amount", Type: "UFix64"}}
+	if len(req.Params) != len(expected) {
+		t.Fatalf("Expected params %v, got: %v", expected, req.Params)
+	}
+	for i := range expected {
+		if req.Params[i] != expected[i] {
+			t.Fatalf("Expected params %v, got: %v", expected, req.Params)
+		}
+	}
+
+	// wrong number of authorizers
+	if err := req.ValidateAuthorizers(nil); !errors.Is(err, ErrAuthorizers) {
+		t.Fatalf("Expected ErrAuthorizers, got: %v", err)
+	}
+	if err := req.Authorize(benchArenaTokenAddr); err != nil {
+		t.Fatalf("Authorize: %v", err)
+	}
+}
+
+func TestTransferAdministratorAuthorizers(t *testing.T) {
+	r := New(benchArenaTokenAddr, benchFungibleTokenAddr)
+	newAdmin := flow.HexToAddress("0x15b169c50310d253")
+
+	req, err := r.BuildTransferAdministrator(benchArenaTokenAddr, newAdmin)
+	if err != nil {
+		t.Fatalf("BuildTransferAdministrator: %v", err)
+	}
+	if len(req.Transaction.Authorizers) != 2 ||
+		req.Transaction.Authorizers[0] != benchArenaTokenAddr ||
+		req.Transaction.Authorizers[1] != newAdmin {
+		t.Fatalf("Unexpected authorizers: %v", req.Transaction.Authorizers)
+	}
+
+	// signers in the wrong order are rejected
+	if err := req.ValidateAuthorizers([]flow.Address{newAdmin, benchArenaTokenAddr}); !errors.Is(err, ErrAuthorizers) {
+		t.Fatalf("Expected ErrAuthorizers, got: %v", err)
+	}
+}
+
+func TestArgumentValidation(t *testing.T) {
+	r := New(benchArenaTokenAddr, benchFungibleTokenAddr)
+	amount, _ := cadence.NewUFix64("10.0")
+
+	// transfer.cdc takes (to: Address, amount: UFix64)
+	if _, err := r.transaction(lookupTemplate(transferPath), 40, amount, cadenceAddress(benchFungibleTokenAddr)); !errors.Is(err, ErrArgumentType) {
+		t.Fatalf("Expected ErrArgumentType for swapped arguments, got: %v", err)
+	}
+	if _, err := r.transaction(lookupTemplate(transferPath), 40, cadenceAddress(benchFungibleTokenAddr)); !errors.Is(err, ErrArgumentCount) {
+		t.Fatalf("Expected ErrArgumentCount for missing argument, got: %v", err)
+	}
+
+	var argErr *ArgumentError
+	_, err := r.transaction(lookupTemplate("cadence/transactions/arenaToken/mint_arena.cdc"), 60, cadenceAddress(benchFungibleTokenAddr), cadence.NewString("10.0"))
+	if !errors.As(err, &argErr) || argErr.Param.Name != "amount" {
+		t.Fatalf("Expected ArgumentError for amount, got: %v", err)
+	}
+}
+
+// fixedEstimator reports the same computation for every transaction
+type fixedEstimator uint64
+
+func (e fixedEstimator) EstimateComputation(context.Context, *TransactionRequest) (uint64, error) {
+	return uint64(e), nil
+}
+
+func TestGasLimit(t *testing.T) {
+	amount, _ := cadence.NewUFix64("10.0")
+
+	r := New(benchArenaTokenAddr, benchFungibleTokenAddr, WithGasLimit(250))
+	req, err := r.BuildTransfer(benchFungibleTokenAddr, amount)
+	if err != nil {
+		t.Fatalf("BuildTransfer: %v", err)
+	}
+	if req.Transaction.GasLimit != 250 {
+		t.Fatalf("Expected gas limit 250, got: %d", req.Transaction.GasLimit)
+	}
+	if err := r.Estimate(context.Background(), req); !errors.Is(err, ErrNoEstimator) {
+		t.Fatalf("Expected ErrNoEstimator, got: %v", err)
+	}
+
+	r = New(benchArenaTokenAddr, benchFungibleTokenAddr, WithEstimator(fixedEstimator(30), 0.25))
+	req, err = r.BuildTransfer(benchFungibleTokenAddr, amount)
+	if err != nil {
+		t.Fatalf("BuildTransfer: %v", err)
+	}
+	if err := r.Estimate(context.Background(), req); err != nil {
+		t.Fatalf("Estimate: %v", err)
+	}
+	if req.Transaction.GasLimit != 38 {
+		t.Fatalf("Expected gas limit 38, got: %d", req.Transaction.GasLimit)
+	}
+
+	// the margin doesn't push the limit past what the network accepts
+	r = New(benchArenaTokenAddr, benchFungibleTokenAddr, WithEstimator(fixedEstimator(9000), 0.25))
+	req, err = r.BuildTransfer(benchFungibleTokenAddr, amount)
+	if err != nil {
+		t.Fatalf("BuildTransfer: %v", err)
+	}
+	if err := r.Estimate(context.Background(), req); err != nil {
+		t.Fatalf("Estimate: %v", err)
+	}
+	if req.Transaction.GasLimit != MaxGasLimit {
+		t.Fatalf("Expected gas limit %d, got: %d", MaxGasLimit, req.Transaction.GasLimit)
+	}
+}
+
+func TestOptions(t *testing.T) {
+	extra := flow.HexToAddress("0x1654653399040a61")
+	r := New(benchArenaTokenAddr, benchFungibleTokenAddr,
+		WithContractName("ArenaGold"),
+		WithPaths(Paths{VaultStorage: "/storage/arenaGoldVault"}),
+		WithImport("FlowToken", extra),
+	)
+
+	req, err := r.BuildSetupAccount()
+	if err != nil {
+		t.Fatalf("BuildSetupAccount: %v", err)
+	}
+	script := string(req.Transaction.Script)
+	if !strings.Contains(script, "import ArenaGold from 0x"+benchArenaTokenAddr.Hex()) ||
+		strings.Contains(script, "ArenaToken.") {
+		t.Fatalf("Expected script importing ArenaGold only, got:\n%s", script)
+	}
+	if r.contracts["FlowToken"] != extra {
+		t.Fatalf("Expected FlowToken import registered, got: %v", r.contracts)
+	}
+
+	code, err := r.ContractCode()
+	if err != nil {
+		t.Fatalf("ContractCode: %v", err)
+	}
+	if !strings.Contains(code, "pub contract ArenaGold:") ||
+		!strings.Contains(code, "self.VaultStoragePath = /storage/arenaGoldVault") ||
+		!strings.Contains(code, "self.ReceiverPublicPath = /public/arenaTokenReceiver") {
+		t.Fatalf("Contract not rendered with options:\n%s", code)
+	}
+
+	// invalid options are reported by builders
+	for _, opt := range []Option{
+		WithContractName("Arena Gold"),
+		WithPaths(Paths{ReceiverPublic: "/storage/arenaGoldReceiver"}),
+		WithImport("0x01", extra),
+	} {
+		if _, err := New(benchArenaTokenAddr, benchFungibleTokenAddr, opt).BuildSetupAccount(); !errors.Is(err, ErrInvalidOption) {
+			t.Fatalf("Expected ErrInvalidOption, got: %v", err)
+		}
+	}
+}
+
+func TestBatchTransferArguments(t *testing.T) {
+	r := New(benchArenaTokenAddr, benchFungibleTokenAddr)
+	one, _ := cadence.NewUFix64("1.0")
+	two, _ := cadence.NewUFix64("2.0")
+	amounts := map[flow.Address]cadence.UFix64{
+		flow.HexToAddress("03"): one,
+		flow.HexToAddress("01"): two,
+		flow.HexToAddress("02"): one,
+	}
+
+	// the same amounts always encode to the same arguments, ordered by address
+	first, err := r.BuildBatchTransfer(amounts, true)
+	if err != nil {
+		t.Fatalf("BuildBatchTransfer: %v", err)
+	}
+	for i := 0; i < 10; i++ {
+		req, err := r.BuildBatchTransfer(amounts, true)
+		if err != nil {
+			t.Fatalf("BuildBatchTransfer: %v", err)
+		}
+		if string(req.Transaction.Arguments[0]) != string(first.Transaction.Arguments[0]) {
+			t.Fatalf("Arguments differ between builds:\n%s\n%s", first.Transaction.Arguments[0], req.Transaction.Arguments[0])
+		}
+	}
+	arg, err := first.Transaction.Argument(0)
+	if err != nil {
+		t.Fatalf("Decoding amounts: %v", err)
+	}
+	pairs := arg.(cadence.Dictionary).Pairs
+	if len(pairs) != 3 || pairs[0].Key != cadenceAddress(flow.HexToAddress("01")) || pairs[0].Value != two {
+		t.Fatalf("Unexpected amounts: %v", arg)
+	}
+
+	req, err := r.BuildBatchTransferArrays([]flow.Address{flow.HexToAddress("01"), flow.HexToAddress("02")}, []cadence.UFix64{one, two}, false)
+	if err != nil {
+		t.Fatalf("BuildBatchTransferArrays: %v", err)
+	}
+	expected := []Param{{Name: "recipients", Type: "[Address]"}, {Name: "amounts", Type: "[UFix64]"}, {Name: "skipMissing", Type: "Bool"}}
+	if len(req.Params) != len(expected) || len(req.Transaction.Arguments) != len(expected) {
+		t.Fatalf("Expected params %v, got: %v", expected, req.Params)
+	}
+	for i := range expected {
+		if req.Params[i] != expected[i] {
+			t.Fatalf("Expected params %v, got: %v", expected, req.Params)
+		}
+	}
+}
+
+func TestBatchTransferChunks(t *testing.T) {
+	r := New(benchArenaTokenAddr, benchFungibleTokenAddr)
+	one, _ := cadence.NewUFix64("1.0")
+
+	// the builders' gas limit covers a full batch
+	req, err := r.BuildBatchTransfer(nil, false)
+	if err != nil {
+		t.Fatalf("BuildBatchTransfer: %v", err)
+	}
+	if limit := batchGasLimit(MaxBatchRecipients); req.Transaction.GasLimit != limit || limit > MaxGasLimit {
+		t.Fatalf("Expected gas limit %d under %d, got: %d", limit, MaxGasLimit, req.Transaction.GasLimit)
+	}
+
+	// a batch over the limit is split by address order
+	amounts := make(map[flow.Address]cadence.UFix64)
+	for i := 0; i < 2*MaxBatchRecipients+1; i++ {
+		amounts[flow.BytesToAddress([]byte{byte(i >> 8), byte(i)})] = one
+	}
+	txs := r.BatchTransfer(amounts, true)
+	sizes := []int{MaxBatchRecipients, MaxBatchRecipients, 1}
+	if len(txs) != len(sizes) {
+		t.Fatalf("Expected %d transactions, got: %d", len(sizes), len(txs))
+	}
+	seen := make(map[cadence.Value]bool)
+	var last []byte
+	for i, tx := range txs {
+		arg, err := tx.Argument(0)
+		if err != nil {
+			t.Fatalf("Decoding amounts: %v", err)
+		}
+		pairs := arg.(cadence.Dictionary).Pairs
+		if len(pairs) != sizes[i] || tx.GasLimit != batchGasLimit(sizes[i]) {
+			t.Fatalf("Expected %d recipients with gas limit %d, got: %d and %d", sizes[i], batchGasLimit(sizes[i]), len(pairs), tx.GasLimit)
+		}
+		for _, pair := range pairs {
+			addr := pair.Key.(cadence.Address)
+			if seen[pair.Key] || bytes.Compare(addr[:], last) <= 0 {
+				t.Fatalf("Recipient %s out of order or paid twice", addr)
+			}
+			seen[pair.Key] = true
+			last = addr[:]
+		}
+	}
+	if len(seen) != len(amounts) {
+		t.Fatalf("Expected %d recipients paid, got: %d", len(amounts), len(seen))
+	}
+	if txs := r.BatchTransfer(nil, false); len(txs) != 0 {
+		t.Fatalf("Expected no transactions for an empty batch, got: %d", len(txs))
+	}
+}
@@ -0,0 +1,100 @@
+package arenatoken
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+
+	"github.com/onflow/cadence/runtime/ast"
+	"github.com/onflow/cadence/runtime/parser2"
+)
+
+// Param is a named, typed parameter declared by a cadence template
+type Param struct {
+	Name string
+	// Type is the cadence type as written in the template, e.g. UFix64 or [Address]
+	Type string
+}
+
+// Signature describes how a transaction or script template is invoked
+type Signature struct {
+	// Params are the transaction(...) or main(...) parameters in declaration order
+	Params []Param
+	// Authorizers are the names of the prepare(...) parameters, one per
+	// authorizing account in the order they must be added to the transaction
+	Authorizers []string
+}
+
+// parseSignature extracts the signature of a parsed go template, and the types of
+// its parameters, by rendering it with placeholder import addresses and parsing the
+// result as cadence. Templates that are neither transactions nor scripts, i.e.
+// contracts, have an empty signature.
+func parseSignature(tpl *template.Template) (Signature, []ast.Type, error) {
+	funcMap := template.FuncMap{
+		"import": func(c string) string {
+			return fmt.Sprintf("import %s from 0x01", c)
+		},
+	}
+	tmpl, err := tpl.Clone()
+	if err != nil {
+		return Signature{}, nil, err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Funcs(funcMap).Execute(&buf, defaultValues); err != nil {
+		return Signature{}, nil, err
+	}
+
+	return signatureOf(buf.String())
+}
+
+// ParseSignature returns the signature of a rendered transaction or script, e.g.
+// one imported from a transaction envelope
+func ParseSignature(script []byte) (Signature, error) {
+	sig, _, err := signatureOf(string(script))
+	return sig, err
+}
+
+// signatureOf parses cadence code and returns its signature and parameter types
+func signatureOf(code string) (Signature, []ast.Type, error) {
+	program, err := parser2.ParseProgram(code)
+	if err != nil {
+		return Signature{}, nil, err
+	}
+
+	var sig Signature
+	var types []ast.Type
+	if txs := program.TransactionDeclarations(); len(txs) > 0 {
+		tx := txs[0]
+		sig.Params, types = params(tx.ParameterList)
+		if tx.Prepare != nil {
+			authorizers, _ := params(tx.Prepare.FunctionDeclaration.ParameterList)
+			for _, p := range authorizers {
+				sig.Authorizers = append(sig.Authorizers, p.Name)
+			}
+		}
+		return sig, types, nil
+	}
+	for _, fn := range program.FunctionDeclarations() {
+		if fn.Identifier.Identifier == "main" {
+			sig.Params, types = params(fn.ParameterList)
+		}
+	}
+
+	return sig, types, nil
+}
+
+func params(list *ast.ParameterList) ([]Param, []ast.Type) {
+	if list == nil {
+		return nil, nil
+	}
+	var out []Param
+	var types []ast.Type
+	for _, p := range list.Parameters {
+		out = append(out, Param{
+			Name: p.Identifier.Identifier,
+			Type: p.TypeAnnotation.Type.String(),
+		})
+		types = append(types, p.TypeAnnotation.Type)
+	}
+	return out, types
+}
@@ -0,0 +1,102 @@
+package arenatoken
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go-sdk"
+)
+
+// VaultStatus reports how far an account is set up to hold tokens
+type VaultStatus struct {
+	// Receiver reports whether the receiver capability links to a receiver
+	Receiver bool
+	// BalanceCapability reports whether the balance capability links to a
+	// token vault
+	BalanceCapability bool
+	// Balance is the vault balance, nil when the balance capability isn't linked
+	Balance *cadence.UFix64
+}
+
+// HasBalanceLink reports whether the balance capability links to a token vault.
+// A vault stored without the link reads as missing, InspectAccount tells such
+// accounts apart from accounts without a vault.
+func (s *VaultStatus) HasBalanceLink() bool {
+	return s.BalanceCapability
+}
+
+// Ready reports whether the account can receive tokens and report its balance
+func (s *VaultStatus) Ready() bool {
+	return s.Receiver && s.BalanceCapability
+}
+
+// DecodeVaultStatus returns the status of a VaultStatus script result
+func DecodeVaultStatus(v cadence.Value) (*VaultStatus, error) {
+	fields, err := structFields(v, "VaultStatus")
+	if err != nil {
+		return nil, err
+	}
+
+	status := &VaultStatus{}
+	var ok bool
+	if status.Receiver, ok = fields["receiver"].(bool); !ok {
+		return nil, fmt.Errorf("%w: VaultStatus.receiver is %T", ErrResultType, fields["receiver"])
+	}
+	if status.BalanceCapability, ok = fields["balanceCapability"].(bool); !ok {
+		return nil, fmt.Errorf("%w: VaultStatus.balanceCapability is %T", ErrResultType, fields["balanceCapability"])
+	}
+	switch balance := fields["balance"].(type) {
+	case nil:
+	case uint64:
+		amount := cadence.UFix64(balance)
+		status.Balance = &amount
+	default:
+		return nil, fmt.Errorf("%w: VaultStatus.balance is %T", ErrResultType, balance)
+	}
+	return status, nil
+}
+
+// VaultStatus returns the vault status of account, without failing for accounts
+// that aren't set up
+func (r *ArenaToken) VaultStatus(ctx context.Context, client ScriptExecutor, account flow.Address) (*VaultStatus, error) {
+	req, err := r.BuildVaultStatus(account)
+	if err != nil {
+		return nil, err
+	}
+	v, err := client.ExecuteScriptAtLatestBlock(ctx, req.Script, req.Arguments)
+	if err != nil {
+		return nil, fmt.Errorf("executing %s: %w", req.Template, err)
+	}
+	return DecodeVaultStatus(v)
+}
+
+// structFields returns the go values of the fields of a struct named name,
+// keyed by field name. Optionals are unwrapped to their value or nil.
+func structFields(v cadence.Value, name string) (map[string]interface{}, error) {
+	values, err := structValues(v, name)
+	if err != nil {
+		return nil, err
+	}
+	fields := make(map[string]interface{}, len(values))
+	for identifier, value := range values {
+		fields[identifier] = value.ToGoValue()
+	}
+	return fields, nil
+}
+
+// structValues returns the cadence values of the fields of a struct named name,
+// keyed by field name
+func structValues(v cadence.Value, name string) (map[string]cadence.Value, error) {
+	s, ok := v.(cadence.Struct)
+	if !ok || s.StructType == nil || s.StructType.QualifiedIdentifier != name {
+		return nil, fmt.Errorf("%w: expected %s, got %T", ErrResultType, name, v)
+	}
+	values := make(map[string]cadence.Value, len(s.Fields))
+	for i, field := range s.StructType.Fields {
+		if i < len(s.Fields) {
+			values[field.Identifier] = s.Fields[i]
+		}
+	}
+	return values, nil
+}
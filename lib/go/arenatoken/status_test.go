@@ -0,0 +1,47 @@
+package arenatoken
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/onflow/cadence"
+)
+
+func TestVaultStatus(t *testing.T) {
+	r := New(benchArenaTokenAddr, benchFungibleTokenAddr)
+	typ := &cadence.StructType{
+		QualifiedIdentifier: "VaultStatus",
+		Fields: []cadence.Field{
+			{Identifier: "receiver", Type: cadence.BoolType{}},
+			{Identifier: "balanceCapability", Type: cadence.BoolType{}},
+			{Identifier: "balance", Type: cadence.OptionalType{Type: cadence.UFix64Type{}}},
+		},
+	}
+	balance, _ := cadence.NewUFix64("12.5")
+
+	client := &fakeExecutor{result: cadence.NewStruct([]cadence.Value{
+		cadence.NewBool(true), cadence.NewBool(true), cadence.NewOptional(balance),
+	}).WithType(typ)}
+	status, err := r.VaultStatus(context.Background(), client, benchFungibleTokenAddr)
+	if err != nil {
+		t.Fatalf("VaultStatus: %v", err)
+	}
+	if !status.Ready() || status.Balance == nil || status.Balance.String() != "12.50000000" {
+		t.Fatalf("Unexpected status: %+v", status)
+	}
+
+	// not set up
+	client.result = cadence.NewStruct([]cadence.Value{
+		cadence.NewBool(false), cadence.NewBool(false), cadence.NewOptional(nil),
+	}).WithType(typ)
+	status, err = r.VaultStatus(context.Background(), client, benchFungibleTokenAddr)
+	if err != nil || status.HasBalanceLink() || status.Receiver || status.Balance != nil {
+		t.Fatalf("Expected empty status, got: %+v %v", status, err)
+	}
+
+	client.result = balance
+	if _, err := r.VaultStatus(context.Background(), client, benchFungibleTokenAddr); !errors.Is(err, ErrResultType) {
+		t.Fatalf("Expected ErrResultType, got: %v", err)
+	}
+}
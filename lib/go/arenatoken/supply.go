@@ -0,0 +1,56 @@
+package arenatoken
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go-sdk"
+	"google.golang.org/grpc"
+)
+
+// ErrResultType is returned when a script returns a value of an unexpected type
+var ErrResultType = errors.New("unexpected script result type")
+
+// DecodeUFix64 returns the amount of a UFix64 script result
+func DecodeUFix64(v cadence.Value) (cadence.UFix64, error) {
+	ufix, ok := v.(cadence.UFix64)
+	if !ok {
+		return 0, fmt.Errorf("%w: expected UFix64, got %T", ErrResultType, v)
+	}
+	return ufix, nil
+}
+
+// ScriptExecutor is the subset of the flow access api used to execute scripts
+type ScriptExecutor interface {
+	ExecuteScriptAtLatestBlock(ctx context.Context, script []byte, arguments []cadence.Value, opts ...grpc.CallOption) (cadence.Value, error)
+}
+
+// TotalSupply returns the total supply of the token contract
+func (r *ArenaToken) TotalSupply(ctx context.Context, client ScriptExecutor) (cadence.UFix64, error) {
+	req, err := r.BuildTotalSupply()
+	if err != nil {
+		return 0, err
+	}
+	return executeUFix64(ctx, client, req)
+}
+
+// CirculatingSupply returns the total supply less the balances of the excluded
+// accounts, such as treasury accounts
+func (r *ArenaToken) CirculatingSupply(ctx context.Context, client ScriptExecutor, excluded []flow.Address) (cadence.UFix64, error) {
+	req, err := r.BuildCirculatingSupply(excluded)
+	if err != nil {
+		return 0, err
+	}
+	return executeUFix64(ctx, client, req)
+}
+
+// executeUFix64 executes a script returning UFix64 at the latest block
+func executeUFix64(ctx context.Context, client ScriptExecutor, req *ScriptRequest) (cadence.UFix64, error) {
+	v, err := client.ExecuteScriptAtLatestBlock(ctx, req.Script, req.Arguments)
+	if err != nil {
+		return 0, fmt.Errorf("executing %s: %w", req.Template, err)
+	}
+	return DecodeUFix64(v)
+}
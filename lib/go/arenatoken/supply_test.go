@@ -0,0 +1,50 @@
+package arenatoken
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go-sdk"
+	"google.golang.org/grpc"
+)
+
+// fakeExecutor returns result for every script and records the last arguments
+type fakeExecutor struct {
+	result cadence.Value
+	script []byte
+	args   []cadence.Value
+}
+
+func (e *fakeExecutor) ExecuteScriptAtLatestBlock(_ context.Context, script []byte, args []cadence.Value, _ ...grpc.CallOption) (cadence.Value, error) {
+	e.script, e.args = script, args
+	return e.result, nil
+}
+
+func TestSupply(t *testing.T) {
+	r := New(benchArenaTokenAddr, benchFungibleTokenAddr)
+	supply, _ := cadence.NewUFix64("100000000000.0")
+	client := &fakeExecutor{result: supply}
+
+	total, err := r.TotalSupply(context.Background(), client)
+	if err != nil {
+		t.Fatalf("TotalSupply: %v", err)
+	}
+	if total.String() != "100000000000.00000000" {
+		t.Fatalf("Unexpected total supply: %s", total)
+	}
+
+	treasury := []flow.Address{benchArenaTokenAddr, benchFungibleTokenAddr}
+	if _, err := r.CirculatingSupply(context.Background(), client, treasury); err != nil {
+		t.Fatalf("CirculatingSupply: %v", err)
+	}
+	if arr, ok := client.args[0].(cadence.Array); !ok || len(arr.Values) != 2 {
+		t.Fatalf("Expected excluded addresses argument, got: %v", client.args)
+	}
+
+	client.result = cadence.NewString("1.0")
+	if _, err := r.TotalSupply(context.Background(), client); !errors.Is(err, ErrResultType) {
+		t.Fatalf("Expected ErrResultType, got: %v", err)
+	}
+}
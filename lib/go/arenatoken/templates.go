@@ -1,37 +1,61 @@
 package arenatoken
 
+//go:generate go run ./internal/buildergen -o builders_gen.go
+
 import (
+	"fmt"
+	"io/fs"
 	"log"
+	"path"
+	"text/template"
+
+	"github.com/onflow/cadence/runtime/ast"
 
 	arenacadence "github.com/arena/arena-cadence"
 )
 
-var (
-	contractTemplate              string
-	setupAccountTemplate          string
-	mintArenaTemplate             string
-	balanceTemplate               string
-	transferTemplate              string
-	transferAdministratorTemplate string
-	destroyAdministratorTemplate  string
-	burnTemplate                  string
-)
+// contractPath is the path of the ArenaToken contract within the embedded fs
+const contractPath = "cadence/contracts/arenatoken.cdc"
+
+// templates holds every embedded cadence file keyed by its path
+var templates = make(map[string]*cadenceTemplate)
 
-// read templates from embedded fs
+// cadenceTemplate is an embedded cadence file parsed once as a go template along
+// with its cadence signature. Parse failures are kept so they can be reported by
+// render rather than at init.
+type cadenceTemplate struct {
+	path string
+	tpl  *template.Template
+	sig  Signature
+	// paramTypes are the parsed types of sig.Params used to validate arguments
+	paramTypes []ast.Type
+	err        error
+}
+
+// read and parse all templates from embedded fs
 func init() {
-	// contracts
-	contractTemplate = readTemplate("cadence/contracts/arenatoken.cdc")
-
-	// transactions
-	setupAccountTemplate = readTemplate("cadence/transactions/arenaToken/setup_account.cdc")
-	mintArenaTemplate = readTemplate("cadence/transactions/arenaToken/mint_arena.cdc")
-	destroyAdministratorTemplate = readTemplate("cadence/transactions/arenaToken/destroy_admin.cdc")
-	transferTemplate = readTemplate("cadence/transactions/arenaToken/transfer.cdc")
-	transferAdministratorTemplate = readTemplate("cadence/transactions/arenaToken/transfer_admin.cdc")
-	burnTemplate = readTemplate("cadence/transactions/arenaToken/burn_arena.cdc")
-
-	// scripts
-	balanceTemplate = readTemplate("cadence/scripts/arenaToken/balance.cdc")
+	err := fs.WalkDir(arenacadence.Cadence, "cadence", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || path.Ext(p) != ".cdc" {
+			return nil
+		}
+		templates[p] = parseTemplate(p)
+		return nil
+	})
+	if err != nil {
+		log.Fatalf("Reading embedded templates: %v", err)
+	}
+}
+
+// lookupTemplate returns the template at path. Unknown paths yield a template
+// whose error is reported when it is rendered.
+func lookupTemplate(path string) *cadenceTemplate {
+	if t, ok := templates[path]; ok {
+		return t
+	}
+	return &cadenceTemplate{path: path, err: fmt.Errorf("missing embedded template: %w", fs.ErrNotExist)}
 }
 
 func readTemplate(path string) string {
@@ -41,3 +65,17 @@ func readTemplate(path string) string {
 	}
 	return string(tpl)
 }
+
+// parseTemplate parses the embedded template at path. The import function is a
+// placeholder that is rebound to the caller's contract addresses on each render.
+func parseTemplate(path string) *cadenceTemplate {
+	funcMap := template.FuncMap{
+		"import": func(string) (string, error) { return "", ErrUnresolvedImport },
+	}
+	tpl, err := template.New(path).Funcs(funcMap).Parse(readTemplate(path))
+	if err != nil {
+		return &cadenceTemplate{path: path, err: err}
+	}
+	sig, paramTypes, err := parseSignature(tpl)
+	return &cadenceTemplate{path: path, tpl: tpl, sig: sig, paramTypes: paramTypes, err: err}
+}
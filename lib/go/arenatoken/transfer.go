@@ -1,24 +1,71 @@
 package arenatoken
 
 import (
+	"bytes"
 	_ "embed"
+	"sort"
 
 	"github.com/onflow/cadence"
-	jsoncdc "github.com/onflow/cadence/encoding/json"
 	"github.com/onflow/flow-go-sdk"
 )
 
-// Transfer returns an unsigned transaction for transfering tokens to the provided account
-func (r *ArenaToken) Transfer(recipient flow.Address, amount cadence.UFix64) *flow.Transaction {
+// MaxBatchRecipients is the number of recipients a batch transfer pays within
+// the gas limit of its builder. BatchTransfer splits larger batches.
+const MaxBatchRecipients = 150
+
+// The gas limit of a batch transfer is a fixed part for the withdrawal plus a
+// conservative part per recipient, for the borrow, deposit and events. A batch
+// of MaxBatchRecipients stays under MaxGasLimit.
+const (
+	batchBaseGas      = 100
+	batchRecipientGas = 60
+)
 
-	tx := render(transferTemplate, nil, r.contracts)
+// batchGasLimit returns the gas limit of a batch transfer paying n recipients
+func batchGasLimit(n int) uint64 {
+	return batchBaseGas + batchRecipientGas*uint64(n)
+}
+
+// Transfer returns an unsigned transaction for transfering tokens to the provided account.
+// It panics if the transaction cannot be built, see BuildTransfer.
+func (r *ArenaToken) Transfer(recipient flow.Address, amount cadence.UFix64) *flow.Transaction {
+	req, err := r.BuildTransfer(recipient, amount)
+	if err != nil {
+		panic(err)
+	}
+	return req.Transaction
+}
 
-	var buf cadence.Address
-	copy(buf[:], recipient.Bytes())
+// BatchTransfer returns unsigned transactions for transfering the amount of each
+// recipient, each withdrawing the total of its recipients from the signer once.
+// Recipients are split by address order into transactions of at most
+// MaxBatchRecipients, whose gas limit is sized to their number of recipients.
+// Recipients without a receiver abort their transaction unless skipMissing is
+// set, in which case their amount stays with the signer and is reported with a
+// BatchRecipientSkipped event. It panics if a transaction cannot be built, see
+// BuildBatchTransfer.
+func (r *ArenaToken) BatchTransfer(amounts map[flow.Address]cadence.UFix64, skipMissing bool) []*flow.Transaction {
+	addrs := make([]flow.Address, 0, len(amounts))
+	for addr := range amounts {
+		addrs = append(addrs, addr)
+	}
+	sort.Slice(addrs, func(i, j int) bool { return bytes.Compare(addrs[i][:], addrs[j][:]) < 0 })
 
-	return flow.NewTransaction().
-		AddRawArgument(jsoncdc.MustEncode(cadence.NewAddress(buf))).
-		AddRawArgument(jsoncdc.MustEncode(amount)).
-		SetScript([]byte(tx)).
-		SetGasLimit(40)
+	var txs []*flow.Transaction
+	for start := 0; start < len(addrs); start += MaxBatchRecipients {
+		end := start + MaxBatchRecipients
+		if end > len(addrs) {
+			end = len(addrs)
+		}
+		chunk := make(map[flow.Address]cadence.UFix64, end-start)
+		for _, addr := range addrs[start:end] {
+			chunk[addr] = amounts[addr]
+		}
+		req, err := r.BuildBatchTransfer(chunk, skipMissing)
+		if err != nil {
+			panic(err)
+		}
+		txs = append(txs, req.Transaction.SetGasLimit(batchGasLimit(len(chunk))))
+	}
+	return txs
 }
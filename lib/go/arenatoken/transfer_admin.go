@@ -7,11 +7,13 @@ import (
 )
 
 // TransferAdministrator returns an unsigned transaction for handing over control of the
-// singular Admin resource controlling the token contract
+// singular Admin resource controlling the token contract. Both the current and the new
+// admin are set as authorizers, in that order, so the transaction must be signed by both.
+// It panics if the transaction cannot be built, see BuildTransferAdministrator.
 func (r *ArenaToken) TransferAdministrator(currentAdmin, newAdmin flow.Address) *flow.Transaction {
-	tx := render(transferAdministratorTemplate, nil, r.contracts)
-
-	return flow.NewTransaction().
-		SetScript([]byte(tx)).
-		SetGasLimit(40)
+	req, err := r.BuildTransferAdministrator(currentAdmin, newAdmin)
+	if err != nil {
+		panic(err)
+	}
+	return req.Transaction
 }
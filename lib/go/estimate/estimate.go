@@ -0,0 +1,221 @@
+// Package estimate provides computation estimators for ArenaToken transactions,
+// used with arenatoken.WithEstimator to set gas limits from measured usage.
+package estimate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/arena/arena-cadence/lib/go/arenatoken"
+	"github.com/onflow/cadence/runtime"
+	"github.com/onflow/flow-go-sdk"
+	"google.golang.org/grpc"
+)
+
+// MaxGasLimit is the highest gas limit accepted by the network
+const MaxGasLimit = arenatoken.MaxGasLimit
+
+var (
+	// ErrNotRecorded is returned by a Recorded estimator for templates it has no usage for
+	ErrNotRecorded = errors.New("no recorded computation for template")
+	// ErrNoReset is returned by an Emulator estimator without a Reset function,
+	// since probes would otherwise run against the state left by earlier probes
+	ErrNoReset = errors.New("no emulator reset configured")
+)
+
+// computationLimitExceededCode is the FVM error code of a transaction that
+// exceeded its computation limit
+const computationLimitExceededCode = 1110
+
+// Client is the subset of the flow access api used to execute probes
+type Client interface {
+	SendTransaction(ctx context.Context, tx flow.Transaction, opts ...grpc.CallOption) error
+	GetTransactionResult(ctx context.Context, txID flow.Identifier, opts ...grpc.CallOption) (*flow.TransactionResult, error)
+}
+
+// Emulator estimates computation by dry-running copies of a transaction on an
+// emulator whose state is a snapshot of the target network. Probes are executed
+// with different gas limits to find the lowest one that doesn't exceed the
+// computation limit. Each probe that succeeds changes the emulator state, so the
+// emulator is reset to the snapshot before every probe.
+type Emulator struct {
+	Client Client
+	// Sign sets the proposal key, payer and reference block of a probe and signs it
+	Sign func(tx *flow.Transaction) error
+	// Reset restores the emulator to the snapshot probes run against, for
+	// example through the snapshot api of the emulator admin server. It is
+	// called before every probe.
+	Reset func(ctx context.Context) error
+	// MaxLimit is the highest gas limit probed, MaxGasLimit if zero
+	MaxLimit uint64
+	// PollInterval is the delay between polls for a probe result, 100ms if zero
+	PollInterval time.Duration
+}
+
+// EstimateComputation returns the lowest gas limit the transaction executes with
+func (e *Emulator) EstimateComputation(ctx context.Context, req *arenatoken.TransactionRequest) (uint64, error) {
+	if e.Reset == nil {
+		return 0, ErrNoReset
+	}
+	hi := e.MaxLimit
+	if hi == 0 {
+		hi = MaxGasLimit
+	}
+
+	// the transaction must succeed with the highest limit, otherwise it fails
+	// for reasons other than computation
+	if exceeded, err := e.probe(ctx, req.Transaction, hi); err != nil {
+		return 0, err
+	} else if exceeded {
+		return 0, fmt.Errorf("computation exceeds limit of %d", hi)
+	}
+
+	lo := uint64(1)
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		exceeded, err := e.probe(ctx, req.Transaction, mid)
+		if err != nil {
+			return 0, err
+		}
+		if exceeded {
+			lo = mid + 1
+		} else {
+			hi = mid
+		}
+	}
+
+	return hi, nil
+}
+
+// probe resets the emulator and executes a copy of tx with the provided gas
+// limit, reporting whether it exceeded the limit. Failures for any other reason
+// are returned as errors.
+func (e *Emulator) probe(ctx context.Context, tx *flow.Transaction, limit uint64) (bool, error) {
+	if err := e.Reset(ctx); err != nil {
+		return false, fmt.Errorf("resetting emulator: %w", err)
+	}
+
+	p := flow.NewTransaction().
+		SetScript(tx.Script).
+		SetGasLimit(limit)
+	p.Arguments = tx.Arguments
+	for _, authorizer := range tx.Authorizers {
+		p.AddAuthorizer(authorizer)
+	}
+	if err := e.Sign(p); err != nil {
+		return false, fmt.Errorf("signing probe: %w", err)
+	}
+
+	if err := e.Client.SendTransaction(ctx, *p); err != nil {
+		return false, fmt.Errorf("sending probe: %w", err)
+	}
+
+	interval := e.PollInterval
+	if interval == 0 {
+		interval = 100 * time.Millisecond
+	}
+	for {
+		result, err := e.Client.GetTransactionResult(ctx, p.ID())
+		if err != nil {
+			return false, fmt.Errorf("probe result: %w", err)
+		}
+		if result.Status == flow.TransactionStatusSealed {
+			if result.Error == nil {
+				return false, nil
+			}
+			if isComputationExceeded(result.Error, limit) {
+				return true, nil
+			}
+			return false, fmt.Errorf("probe with gas limit %d: %w", limit, result.Error)
+		}
+
+		select {
+		case <-ctx.Done():
+			return false, ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
+// isComputationExceeded reports whether err is the failure of a transaction that
+// exceeded limit. Results only carry the error message, so it is matched against
+// the FVM error code or the message of the cadence ComputationLimitExceededError.
+func isComputationExceeded(err error, limit uint64) bool {
+	msg := err.Error()
+	return strings.Contains(msg, fmt.Sprintf("[Error Code: %d]", computationLimitExceededCode)) ||
+		strings.Contains(msg, runtime.ComputationLimitExceededError{Limit: limit}.Error())
+}
+
+// Recorded estimates computation from previously measured usage keyed by template path
+type Recorded map[string]uint64
+
+// EstimateComputation returns the recorded computation for the template of the request
+func (r Recorded) EstimateComputation(_ context.Context, req *arenatoken.TransactionRequest) (uint64, error) {
+	used, ok := r[req.Template]
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", ErrNotRecorded, req.Template)
+	}
+	return used, nil
+}
+
+// LoadRecorded reads recorded usage from its json form, a template path to computation object
+func LoadRecorded(reader io.Reader) (Recorded, error) {
+	r := make(Recorded)
+	if err := json.NewDecoder(reader).Decode(&r); err != nil {
+		return nil, fmt.Errorf("decoding recorded computation: %w", err)
+	}
+	return r, nil
+}
+
+// Save writes recorded usage in the form read by LoadRecorded
+func (r Recorded) Save(writer io.Writer) error {
+	enc := json.NewEncoder(writer)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// Recorder passes estimates through from Estimator and records them, typically
+// to save emulator measurements for offline use with a Recorded estimator
+type Recorder struct {
+	Estimator arenatoken.ComputationEstimator
+
+	mu       sync.Mutex
+	recorded Recorded
+}
+
+// EstimateComputation returns and records the estimate of the wrapped estimator.
+// The highest estimate seen for a template is kept.
+func (r *Recorder) EstimateComputation(ctx context.Context, req *arenatoken.TransactionRequest) (uint64, error) {
+	used, err := r.Estimator.EstimateComputation(ctx, req)
+	if err != nil {
+		return 0, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.recorded == nil {
+		r.recorded = make(Recorded)
+	}
+	if used > r.recorded[req.Template] {
+		r.recorded[req.Template] = used
+	}
+	return used, nil
+}
+
+// Recorded returns a copy of the usage recorded so far
+func (r *Recorder) Recorded() Recorded {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	out := make(Recorded, len(r.recorded))
+	for k, v := range r.recorded {
+		out[k] = v
+	}
+	return out
+}
@@ -0,0 +1,135 @@
+package estimate
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/arena/arena-cadence/lib/go/arenatoken"
+	"github.com/onflow/cadence"
+	"github.com/onflow/cadence/runtime"
+	"github.com/onflow/flow-go-sdk"
+	"google.golang.org/grpc"
+)
+
+// fakeClient seals every transaction, failing those with a gas limit below the
+// computation they use. Like setup_account, a transaction uses used until one
+// succeeds and a third of it afterwards, until the state is reset.
+type fakeClient struct {
+	used    uint64
+	applied bool
+	resets  int
+	sent    int
+	results map[flow.Identifier]*flow.TransactionResult
+}
+
+func (c *fakeClient) SendTransaction(_ context.Context, tx flow.Transaction, _ ...grpc.CallOption) error {
+	c.sent++
+	used := c.used
+	if c.applied {
+		used /= 3
+	}
+	result := &flow.TransactionResult{Status: flow.TransactionStatusSealed}
+	if tx.GasLimit < used {
+		result.Error = runtime.ComputationLimitExceededError{Limit: tx.GasLimit}
+	} else {
+		c.applied = true
+	}
+	c.results[tx.ID()] = result
+	return nil
+}
+
+func (c *fakeClient) GetTransactionResult(_ context.Context, id flow.Identifier, _ ...grpc.CallOption) (*flow.TransactionResult, error) {
+	return c.results[id], nil
+}
+
+func (c *fakeClient) reset(context.Context) error {
+	c.resets++
+	c.applied = false
+	return nil
+}
+
+func newEmulator(client *fakeClient) *Emulator {
+	return &Emulator{Client: client, Sign: func(*flow.Transaction) error { return nil }, Reset: client.reset}
+}
+
+func transferRequest(t *testing.T) *arenatoken.TransactionRequest {
+	r := arenatoken.New(flow.HexToAddress("0x01cf0e2f2f715450"), flow.HexToAddress("0xee82856bf20e2aa6"))
+	amount, _ := cadence.NewUFix64("10.0")
+	req, err := r.BuildTransfer(flow.HexToAddress("0x179b6b1cb6755e31"), amount)
+	if err != nil {
+		t.Fatalf("BuildTransfer: %v", err)
+	}
+	return req
+}
+
+func TestEmulator(t *testing.T) {
+	client := &fakeClient{used: 27, results: make(map[flow.Identifier]*flow.TransactionResult)}
+	e := newEmulator(client)
+
+	// every probe runs against the reset state, not the one left by the first
+	used, err := e.EstimateComputation(context.Background(), transferRequest(t))
+	if err != nil {
+		t.Fatalf("EstimateComputation: %v", err)
+	}
+	if used != 27 {
+		t.Fatalf("Expected 27, got: %d", used)
+	}
+	if client.resets != client.sent {
+		t.Fatalf("Expected a reset before each of %d probes, got: %d", client.sent, client.resets)
+	}
+
+	client.used = MaxGasLimit + 1
+	if _, err := e.EstimateComputation(context.Background(), transferRequest(t)); err == nil {
+		t.Fatal("Expected error for computation above MaxGasLimit")
+	}
+
+	e.Reset = nil
+	if _, err := e.EstimateComputation(context.Background(), transferRequest(t)); !errors.Is(err, ErrNoReset) {
+		t.Fatalf("Expected ErrNoReset, got: %v", err)
+	}
+}
+
+func TestComputationExceeded(t *testing.T) {
+	for _, test := range []struct {
+		err      error
+		exceeded bool
+	}{
+		{runtime.ComputationLimitExceededError{Limit: 40}, true},
+		{errors.New("[Error Code: 1110] computation exceeds limit (40)"), true},
+		{runtime.ComputationLimitExceededError{Limit: 41}, false},
+		{errors.New("panic: Could not borrow reference to the owner's Vault!"), false},
+	} {
+		if isComputationExceeded(test.err, 40) != test.exceeded {
+			t.Fatalf("Expected %v for %q", test.exceeded, test.err)
+		}
+	}
+}
+
+func TestRecorded(t *testing.T) {
+	client := &fakeClient{used: 27, results: make(map[flow.Identifier]*flow.TransactionResult)}
+	rec := &Recorder{Estimator: newEmulator(client)}
+	req := transferRequest(t)
+	if _, err := rec.EstimateComputation(context.Background(), req); err != nil {
+		t.Fatalf("EstimateComputation: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := rec.Recorded().Save(&buf); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	recorded, err := LoadRecorded(&buf)
+	if err != nil {
+		t.Fatalf("LoadRecorded: %v", err)
+	}
+	used, err := recorded.EstimateComputation(context.Background(), req)
+	if err != nil || used != 27 {
+		t.Fatalf("Expected 27, got: %d, %v", used, err)
+	}
+
+	delete(recorded, req.Template)
+	if _, err := recorded.EstimateComputation(context.Background(), req); !errors.Is(err, ErrNotRecorded) {
+		t.Fatalf("Expected ErrNotRecorded, got: %v", err)
+	}
+}
@@ -0,0 +1,297 @@
+// Package events decodes the events emitted by an ArenaToken contract into Go
+// types. Event type identifiers are derived from the address and name the
+// contract is deployed with.
+package events
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/arena/arena-cadence/lib/go/arenatoken"
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go-sdk"
+)
+
+var (
+	// ErrUnknownEvent is returned when decoding an event that isn't emitted by
+	// the token contract
+	ErrUnknownEvent = errors.New("unknown token contract event")
+	// ErrMalformedEvent is returned when an event doesn't have the fields its
+	// contract declares
+	ErrMalformedEvent = errors.New("malformed event")
+)
+
+// Event is one of the event types of this package
+type Event interface {
+	// Name returns the name of the event in the contract
+	Name() string
+	// Metadata returns the transaction and position the event was emitted at
+	Metadata() Meta
+}
+
+// Meta locates an event within its block
+type Meta struct {
+	Type             string
+	TransactionID    flow.Identifier
+	TransactionIndex int
+	EventIndex       int
+}
+
+// Metadata returns m
+func (m Meta) Metadata() Meta {
+	return m
+}
+
+// TokensInitialized is emitted once when the contract is deployed
+type TokensInitialized struct {
+	Meta
+	InitialSupply cadence.UFix64
+}
+
+// TokensWithdrawn is emitted when tokens are withdrawn from a vault. From is nil
+// for vaults not stored in an account.
+type TokensWithdrawn struct {
+	Meta
+	Amount cadence.UFix64
+	From   *flow.Address
+}
+
+// TokensDeposited is emitted when tokens are deposited to a vault. To is nil
+// for vaults not stored in an account.
+type TokensDeposited struct {
+	Meta
+	Amount cadence.UFix64
+	To     *flow.Address
+}
+
+// TokensMinted is emitted when new tokens are minted
+type TokensMinted struct {
+	Meta
+	Amount cadence.UFix64
+}
+
+// TokensBurned is emitted when tokens are destroyed
+type TokensBurned struct {
+	Meta
+	Amount cadence.UFix64
+}
+
+// MinterCreated is emitted when a minter resource is created
+type MinterCreated struct {
+	Meta
+	AllowedAmount cadence.UFix64
+}
+
+// BurnerCreated is emitted when a burner resource is created
+type BurnerCreated struct {
+	Meta
+}
+
+// BatchRecipientSkipped is emitted when a batch transfer skips a recipient
+// without a receiver capability, leaving Amount with the sender
+type BatchRecipientSkipped struct {
+	Meta
+	To     flow.Address
+	Amount cadence.UFix64
+}
+
+// AdministratorDestroyed is emitted when the Administrator resource is destroyed
+type AdministratorDestroyed struct {
+	Meta
+}
+
+// AdministratorOffered is emitted when the Administrator resource is offered to recipient
+type AdministratorOffered struct {
+	Meta
+	Recipient flow.Address
+}
+
+// AdministratorClaimed is emitted when an offered Administrator resource is claimed
+type AdministratorClaimed struct {
+	Meta
+	Recipient flow.Address
+}
+
+func (TokensInitialized) Name() string      { return "TokensInitialized" }
+func (TokensWithdrawn) Name() string        { return "TokensWithdrawn" }
+func (TokensDeposited) Name() string        { return "TokensDeposited" }
+func (TokensMinted) Name() string           { return "TokensMinted" }
+func (TokensBurned) Name() string           { return "TokensBurned" }
+func (MinterCreated) Name() string          { return "MinterCreated" }
+func (BurnerCreated) Name() string          { return "BurnerCreated" }
+func (BatchRecipientSkipped) Name() string  { return "BatchRecipientSkipped" }
+func (AdministratorDestroyed) Name() string { return "AdministratorDestroyed" }
+func (AdministratorOffered) Name() string   { return "AdministratorOffered" }
+func (AdministratorClaimed) Name() string   { return "AdministratorClaimed" }
+
+// decoders build each event from its metadata and fields keyed by name
+var decoders = map[string]func(Meta, fields) (Event, error){
+	"TokensInitialized": func(m Meta, f fields) (Event, error) {
+		supply, err := f.ufix64("initialSupply")
+		return &TokensInitialized{Meta: m, InitialSupply: supply}, err
+	},
+	"TokensWithdrawn": func(m Meta, f fields) (Event, error) {
+		amount, err := f.ufix64("amount")
+		if err != nil {
+			return nil, err
+		}
+		from, err := f.optionalAddress("from")
+		return &TokensWithdrawn{Meta: m, Amount: amount, From: from}, err
+	},
+	"TokensDeposited": func(m Meta, f fields) (Event, error) {
+		amount, err := f.ufix64("amount")
+		if err != nil {
+			return nil, err
+		}
+		to, err := f.optionalAddress("to")
+		return &TokensDeposited{Meta: m, Amount: amount, To: to}, err
+	},
+	"TokensMinted": func(m Meta, f fields) (Event, error) {
+		amount, err := f.ufix64("amount")
+		return &TokensMinted{Meta: m, Amount: amount}, err
+	},
+	"TokensBurned": func(m Meta, f fields) (Event, error) {
+		amount, err := f.ufix64("amount")
+		return &TokensBurned{Meta: m, Amount: amount}, err
+	},
+	"MinterCreated": func(m Meta, f fields) (Event, error) {
+		amount, err := f.ufix64("allowedAmount")
+		return &MinterCreated{Meta: m, AllowedAmount: amount}, err
+	},
+	"BurnerCreated": func(m Meta, _ fields) (Event, error) {
+		return &BurnerCreated{Meta: m}, nil
+	},
+	"BatchRecipientSkipped": func(m Meta, f fields) (Event, error) {
+		to, err := f.address("to")
+		if err != nil {
+			return nil, err
+		}
+		amount, err := f.ufix64("amount")
+		return &BatchRecipientSkipped{Meta: m, To: to, Amount: amount}, err
+	},
+	"AdministratorDestroyed": func(m Meta, _ fields) (Event, error) {
+		return &AdministratorDestroyed{Meta: m}, nil
+	},
+	"AdministratorOffered": func(m Meta, f fields) (Event, error) {
+		recipient, err := f.address("recipient")
+		return &AdministratorOffered{Meta: m, Recipient: recipient}, err
+	},
+	"AdministratorClaimed": func(m Meta, f fields) (Event, error) {
+		recipient, err := f.address("recipient")
+		return &AdministratorClaimed{Meta: m, Recipient: recipient}, err
+	},
+}
+
+// Decoder decodes the events of a token contract deployment
+type Decoder struct {
+	// prefix is the type identifier prefix of the contract, A.<address>.<name>.
+	prefix string
+}
+
+// NewDecoder returns a decoder for the events of the token contract deployed at
+// address with the given name, ArenaToken if empty
+func NewDecoder(address flow.Address, contract string) *Decoder {
+	if contract == "" {
+		contract = arenatoken.DefaultContractName
+	}
+	return &Decoder{prefix: fmt.Sprintf("A.%s.%s.", address.Hex(), contract)}
+}
+
+// For returns a decoder for the events of the token contract of r
+func For(r *arenatoken.ArenaToken) *Decoder {
+	return NewDecoder(r.ContractAddress(), r.ContractName())
+}
+
+// TypeID returns the type identifier of the named event
+func (d *Decoder) TypeID(name string) string {
+	return d.prefix + name
+}
+
+// TypeIDs returns the type identifiers of every event of the contract, sorted
+func (d *Decoder) TypeIDs() []string {
+	ids := make([]string, 0, len(decoders))
+	for name := range decoders {
+		ids = append(ids, d.TypeID(name))
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Decode returns the typed event for e. It fails with ErrUnknownEvent for
+// events of other contracts or unknown names.
+func (d *Decoder) Decode(e flow.Event) (Event, error) {
+	name := strings.TrimPrefix(e.Type, d.prefix)
+	decode, ok := decoders[name]
+	if !ok || len(name) == len(e.Type) {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownEvent, e.Type)
+	}
+
+	f := make(fields, len(e.Value.Fields))
+	if e.Value.EventType != nil {
+		for i, field := range e.Value.EventType.Fields {
+			if i < len(e.Value.Fields) {
+				f[field.Identifier] = e.Value.Fields[i]
+			}
+		}
+	}
+	meta := Meta{Type: e.Type, TransactionID: e.TransactionID, TransactionIndex: e.TransactionIndex, EventIndex: e.EventIndex}
+	ev, err := decode(meta, f)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrMalformedEvent, e.Type, err)
+	}
+	return ev, nil
+}
+
+// FromResult decodes the token contract events of a transaction result in the
+// order they were emitted, skipping events of other contracts
+func (d *Decoder) FromResult(result *flow.TransactionResult) ([]Event, error) {
+	var out []Event
+	for _, e := range result.Events {
+		if !strings.HasPrefix(e.Type, d.prefix) {
+			continue
+		}
+		ev, err := d.Decode(e)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, ev)
+	}
+	return out, nil
+}
+
+// fields are the values of an event keyed by field name
+type fields map[string]cadence.Value
+
+func (f fields) ufix64(name string) (cadence.UFix64, error) {
+	v, ok := f[name].(cadence.UFix64)
+	if !ok {
+		return 0, fmt.Errorf("field %s: expected UFix64, got %T", name, f[name])
+	}
+	return v, nil
+}
+
+func (f fields) address(name string) (flow.Address, error) {
+	v, ok := f[name].(cadence.Address)
+	if !ok {
+		return flow.EmptyAddress, fmt.Errorf("field %s: expected Address, got %T", name, f[name])
+	}
+	return flow.BytesToAddress(v.Bytes()), nil
+}
+
+func (f fields) optionalAddress(name string) (*flow.Address, error) {
+	v, ok := f[name].(cadence.Optional)
+	if !ok {
+		return nil, fmt.Errorf("field %s: expected Address?, got %T", name, f[name])
+	}
+	if v.Value == nil {
+		return nil, nil
+	}
+	addr, ok := v.Value.(cadence.Address)
+	if !ok {
+		return nil, fmt.Errorf("field %s: expected Address?, got optional %T", name, v.Value)
+	}
+	a := flow.BytesToAddress(addr.Bytes())
+	return &a, nil
+}
@@ -0,0 +1,87 @@
+package events
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/arena/arena-cadence/lib/go/arenatoken"
+	"github.com/onflow/cadence"
+	jsoncdc "github.com/onflow/cadence/encoding/json"
+	"github.com/onflow/flow-go-sdk"
+)
+
+var (
+	tokenAddr    = flow.HexToAddress("0x0996b5100d5c8ad6")
+	fungibleAddr = flow.HexToAddress("0x9a0766d93b6608b7")
+)
+
+// event decodes a json-cdc event payload as the access api does
+func event(t *testing.T, payload string) flow.Event {
+	t.Helper()
+
+	v, err := jsoncdc.Decode([]byte(payload))
+	if err != nil {
+		t.Fatalf("Decoding payload: %v", err)
+	}
+	ev := v.(cadence.Event)
+	return flow.Event{Type: ev.EventType.ID(), TransactionID: flow.HexToID("0a"), EventIndex: 1, Value: ev}
+}
+
+func TestDecode(t *testing.T) {
+	d := For(arenatoken.New(tokenAddr, fungibleAddr))
+	if id := d.TypeID("TokensDeposited"); id != "A.0996b5100d5c8ad6.ArenaToken.TokensDeposited" {
+		t.Fatalf("Unexpected type id: %s", id)
+	}
+
+	deposit := event(t, `{"type":"Event","value":{"id":"A.0996b5100d5c8ad6.ArenaToken.TokensDeposited","fields":[
+		{"name":"amount","value":{"type":"UFix64","value":"10.50000000"}},
+		{"name":"to","value":{"type":"Optional","value":{"type":"Address","value":"0x01cf0e2f2f715450"}}}]}}`)
+	withdraw := event(t, `{"type":"Event","value":{"id":"A.0996b5100d5c8ad6.ArenaToken.TokensWithdrawn","fields":[
+		{"name":"amount","value":{"type":"UFix64","value":"10.50000000"}},
+		{"name":"from","value":{"type":"Optional","value":null}}]}}`)
+	fee := event(t, `{"type":"Event","value":{"id":"A.7e60df042a9c0868.FlowToken.TokensWithdrawn","fields":[
+		{"name":"amount","value":{"type":"UFix64","value":"0.00010000"}},
+		{"name":"from","value":{"type":"Optional","value":null}}]}}`)
+
+	evs, err := d.FromResult(&flow.TransactionResult{Events: []flow.Event{withdraw, fee, deposit}})
+	if err != nil {
+		t.Fatalf("FromResult: %v", err)
+	}
+	if len(evs) != 2 {
+		t.Fatalf("Expected 2 events, got: %d", len(evs))
+	}
+	w, ok := evs[0].(*TokensWithdrawn)
+	if !ok || w.From != nil || w.Amount.String() != "10.50000000" {
+		t.Fatalf("Unexpected withdrawal: %+v", evs[0])
+	}
+	dep, ok := evs[1].(*TokensDeposited)
+	if !ok || dep.To == nil || *dep.To != flow.HexToAddress("0x01cf0e2f2f715450") || dep.Metadata().EventIndex != 1 {
+		t.Fatalf("Unexpected deposit: %+v", evs[1])
+	}
+
+	if _, err := d.Decode(fee); !errors.Is(err, ErrUnknownEvent) {
+		t.Fatalf("Expected ErrUnknownEvent, got: %v", err)
+	}
+	malformed := event(t, `{"type":"Event","value":{"id":"A.0996b5100d5c8ad6.ArenaToken.TokensMinted","fields":[
+		{"name":"amount","value":{"type":"String","value":"10.0"}}]}}`)
+	if _, err := d.Decode(malformed); !errors.Is(err, ErrMalformedEvent) {
+		t.Fatalf("Expected ErrMalformedEvent, got: %v", err)
+	}
+
+	skipped := event(t, `{"type":"Event","value":{"id":"A.0996b5100d5c8ad6.ArenaToken.BatchRecipientSkipped","fields":[
+		{"name":"to","value":{"type":"Address","value":"0x01cf0e2f2f715450"}},
+		{"name":"amount","value":{"type":"UFix64","value":"2.00000000"}}]}}`)
+	ev, err := d.Decode(skipped)
+	if s, ok := ev.(*BatchRecipientSkipped); err != nil || !ok || s.To != flow.HexToAddress("0x01cf0e2f2f715450") || s.Amount.String() != "2.00000000" {
+		t.Fatalf("Unexpected skipped recipient: %+v %v", ev, err)
+	}
+
+	// a named deployment has its own type ids
+	gold := For(arenatoken.New(tokenAddr, fungibleAddr, arenatoken.WithContractName("ArenaGold")))
+	if _, err := gold.Decode(deposit); !errors.Is(err, ErrUnknownEvent) {
+		t.Fatalf("Expected ErrUnknownEvent for ArenaToken event, got: %v", err)
+	}
+	if len(gold.TypeIDs()) != 11 || gold.TypeIDs()[0] != "A.0996b5100d5c8ad6.ArenaGold.AdministratorClaimed" {
+		t.Fatalf("Unexpected type ids: %v", gold.TypeIDs())
+	}
+}
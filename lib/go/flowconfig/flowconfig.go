@@ -0,0 +1,372 @@
+// Package flowconfig loads contract addresses, networks and accounts from a Flow
+// project configuration file, flow.json, as used by the flow CLI.
+package flowconfig
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/arena/arena-cadence/lib/go/arenatoken"
+	"github.com/arena/arena-cadence/lib/go/signing"
+	"github.com/onflow/flow-go-sdk"
+	"github.com/onflow/flow-go-sdk/crypto"
+)
+
+// FileName is the conventional name of the project configuration file
+const FileName = "flow.json"
+
+var (
+	// ErrUnknownNetwork is returned for networks not declared in the configuration
+	ErrUnknownNetwork = errors.New("unknown network")
+	// ErrUnknownAccount is returned for accounts not declared in the configuration
+	ErrUnknownAccount = errors.New("unknown account")
+	// ErrUnsupportedKey is returned when decoding a key that isn't a hex private key
+	ErrUnsupportedKey = errors.New("unsupported key type")
+)
+
+// Config is a parsed flow.json
+type Config struct {
+	Emulators map[string]Emulator `json:"emulators"`
+	Contracts map[string]Contract `json:"contracts"`
+	Networks  map[string]Network  `json:"networks"`
+	Accounts  map[string]Account  `json:"accounts"`
+	// Deployments lists the contracts deployed to each account keyed by network
+	// then account name
+	Deployments map[string]map[string][]Deployment `json:"deployments"`
+}
+
+// Emulator configures a local emulator
+type Emulator struct {
+	Port           int    `json:"port"`
+	ServiceAccount string `json:"serviceAccount"`
+}
+
+// Contract is a contract source along with the addresses it is already deployed
+// to keyed by network
+type Contract struct {
+	Source  string                  `json:"source"`
+	Aliases map[string]flow.Address `json:"-"`
+}
+
+// UnmarshalJSON accepts the short form of a source path or the long form object
+func (c *Contract) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &c.Source); err == nil {
+		return nil
+	}
+
+	var long struct {
+		Source  string            `json:"source"`
+		Aliases map[string]string `json:"aliases"`
+	}
+	if err := json.Unmarshal(data, &long); err != nil {
+		return err
+	}
+	c.Source = long.Source
+	c.Aliases = make(map[string]flow.Address, len(long.Aliases))
+	for network, addr := range long.Aliases {
+		c.Aliases[network] = flow.HexToAddress(addr)
+	}
+	return nil
+}
+
+// Network is an access node and the chain it serves
+type Network struct {
+	Host  string       `json:"host"`
+	Chain flow.ChainID `json:"chain"`
+}
+
+// UnmarshalJSON accepts the short form of a host or the long form object
+func (n *Network) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &n.Host); err == nil {
+		return nil
+	}
+
+	var long struct {
+		Host  string `json:"host"`
+		Chain string `json:"chain"`
+	}
+	if err := json.Unmarshal(data, &long); err != nil {
+		return err
+	}
+	n.Host, n.Chain = long.Host, flow.ChainID(long.Chain)
+	return nil
+}
+
+// Account is a flow account and the keys configured to sign for it
+type Account struct {
+	Address flow.Address
+	Keys    []Key
+}
+
+// UnmarshalJSON accepts a single key, in short or long form, as "key" or a list
+// of long form keys as "keys"
+func (a *Account) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Address string          `json:"address"`
+		Key     json.RawMessage `json:"key"`
+		Keys    json.RawMessage `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	a.Address = flow.HexToAddress(raw.Address)
+
+	if len(raw.Key) > 0 {
+		var key Key
+		if err := json.Unmarshal(raw.Key, &key); err != nil {
+			return err
+		}
+		a.Keys = []Key{key}
+	}
+	if len(raw.Keys) > 0 {
+		// keys was a single short form key in older configurations
+		var key Key
+		if err := json.Unmarshal(raw.Keys, &key); err == nil {
+			a.Keys = append(a.Keys, key)
+			return nil
+		}
+		var keys []Key
+		if err := json.Unmarshal(raw.Keys, &keys); err != nil {
+			return err
+		}
+		a.Keys = append(a.Keys, keys...)
+	}
+	return nil
+}
+
+// Key is an account key. Only hex encoded private keys can be decoded, other
+// types such as google-kms are kept so the account can still be inspected.
+type Key struct {
+	Type     string
+	Index    int
+	SigAlgo  crypto.SignatureAlgorithm
+	HashAlgo crypto.HashAlgorithm
+	// PrivateKey is the hex encoded key, environment variables written as $NAME
+	// are expanded when it is decoded
+	PrivateKey string
+}
+
+// UnmarshalJSON accepts the short form of a hex private key or the long form object
+func (k *Key) UnmarshalJSON(data []byte) error {
+	*k = Key{Type: "hex", SigAlgo: crypto.ECDSA_P256, HashAlgo: crypto.SHA3_256}
+	if err := json.Unmarshal(data, &k.PrivateKey); err == nil {
+		return nil
+	}
+
+	var long struct {
+		Type     string `json:"type"`
+		Index    int    `json:"index"`
+		SigAlgo  string `json:"signatureAlgorithm"`
+		HashAlgo string `json:"hashAlgorithm"`
+		// privateKey is nested in context in older configurations
+		PrivateKey string `json:"privateKey"`
+		Context    struct {
+			PrivateKey string `json:"privateKey"`
+		} `json:"context"`
+	}
+	if err := json.Unmarshal(data, &long); err != nil {
+		return err
+	}
+	if long.Type != "" {
+		k.Type = long.Type
+	}
+	k.Index = long.Index
+	if long.SigAlgo != "" {
+		if k.SigAlgo = crypto.StringToSignatureAlgorithm(long.SigAlgo); k.SigAlgo == crypto.UnknownSignatureAlgorithm {
+			return fmt.Errorf("unknown signature algorithm %q", long.SigAlgo)
+		}
+	}
+	if long.HashAlgo != "" {
+		if k.HashAlgo = crypto.StringToHashAlgorithm(long.HashAlgo); k.HashAlgo == crypto.UnknownHashAlgorithm {
+			return fmt.Errorf("unknown hash algorithm %q", long.HashAlgo)
+		}
+	}
+	k.PrivateKey = long.PrivateKey
+	if k.PrivateKey == "" {
+		k.PrivateKey = long.Context.PrivateKey
+	}
+	return nil
+}
+
+// Decode returns the private key
+func (k Key) Decode() (crypto.PrivateKey, error) {
+	if k.Type != "hex" {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedKey, k.Type)
+	}
+	return crypto.DecodePrivateKeyHex(k.SigAlgo, strings.TrimPrefix(os.ExpandEnv(k.PrivateKey), "0x"))
+}
+
+// KeyConfig returns the decoded key for use with a signing.Keyring
+func (k Key) KeyConfig() (signing.KeyConfig, error) {
+	priv, err := k.Decode()
+	if err != nil {
+		return signing.KeyConfig{}, err
+	}
+	return signing.KeyConfig{Index: k.Index, PrivateKey: priv, HashAlgo: k.HashAlgo}, nil
+}
+
+// KeyConfigs returns the decoded keys of the account for use with a signing.Keyring
+func (a Account) KeyConfigs() ([]signing.KeyConfig, error) {
+	configs := make([]signing.KeyConfig, len(a.Keys))
+	for i, key := range a.Keys {
+		c, err := key.KeyConfig()
+		if err != nil {
+			return nil, fmt.Errorf("account %s key %d: %w", a.Address, key.Index, err)
+		}
+		configs[i] = c
+	}
+	return configs, nil
+}
+
+// Deployment is a contract deployed to an account
+type Deployment struct {
+	Name string `json:"name"`
+}
+
+// UnmarshalJSON accepts the short form of a contract name or the long form object
+func (d *Deployment) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &d.Name); err == nil {
+		return nil
+	}
+	var long struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(data, &long); err != nil {
+		return err
+	}
+	d.Name = long.Name
+	return nil
+}
+
+// Parse parses the contents of a flow.json
+func Parse(data []byte) (*Config, error) {
+	var c Config
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("parsing flow config: %w", err)
+	}
+	return &c, nil
+}
+
+// Load reads and parses the flow.json at path
+func Load(path string) (*Config, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading flow config: %w", err)
+	}
+	return Parse(data)
+}
+
+// Find returns the path of the nearest flow.json in the working directory or
+// one of its parents
+func Find() (string, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	for {
+		path := filepath.Join(dir, FileName)
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", fmt.Errorf("no %s in working directory or its parents: %w", FileName, os.ErrNotExist)
+		}
+		dir = parent
+	}
+}
+
+// ChainID returns the chain served by a network. Networks without an explicit
+// chain are matched by name to the emulator, testnet and mainnet chains.
+func (c *Config) ChainID(network string) (flow.ChainID, error) {
+	n, ok := c.Networks[network]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrUnknownNetwork, network)
+	}
+	if n.Chain != "" {
+		return n.Chain, nil
+	}
+	switch network {
+	case "emulator":
+		return flow.Emulator, nil
+	case "testnet":
+		return flow.Testnet, nil
+	case "mainnet":
+		return flow.Mainnet, nil
+	}
+	return "", fmt.Errorf("network %s has no chain", network)
+}
+
+// Addresses returns the address of every contract on a network, taken from its
+// alias or else the account it is deployed to
+func (c *Config) Addresses(network string) (map[string]flow.Address, error) {
+	if _, ok := c.Networks[network]; !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownNetwork, network)
+	}
+
+	contracts := make(map[string]flow.Address)
+	for account, deployments := range c.Deployments[network] {
+		acct, ok := c.Accounts[account]
+		if !ok {
+			return nil, fmt.Errorf("deployment on %s: %w: %s", network, ErrUnknownAccount, account)
+		}
+		for _, d := range deployments {
+			contracts[d.Name] = acct.Address
+		}
+	}
+	for name, contract := range c.Contracts {
+		if addr, ok := contract.Aliases[network]; ok {
+			contracts[name] = addr
+		}
+	}
+	return contracts, nil
+}
+
+// ArenaToken returns a handle importing every contract of a network, see
+// arenatoken.FromAddresses
+func (c *Config) ArenaToken(network string, opts ...arenatoken.Option) (*arenatoken.ArenaToken, error) {
+	contracts, err := c.Addresses(network)
+	if err != nil {
+		return nil, err
+	}
+	r, err := arenatoken.FromAddresses(contracts, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", network, err)
+	}
+	return r, nil
+}
+
+// Account returns a configured account
+func (c *Config) Account(name string) (Account, error) {
+	a, ok := c.Accounts[name]
+	if !ok {
+		return Account{}, fmt.Errorf("%w: %s", ErrUnknownAccount, name)
+	}
+	return a, nil
+}
+
+// EmulatorServiceAccount returns the service account of the default emulator,
+// or of the only emulator when there is no default
+func (c *Config) EmulatorServiceAccount() (Account, error) {
+	e, ok := c.Emulators["default"]
+	if !ok && len(c.Emulators) == 1 {
+		for _, only := range c.Emulators {
+			e, ok = only, true
+		}
+	}
+	if !ok {
+		names := make([]string, 0, len(c.Emulators))
+		for name := range c.Emulators {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return Account{}, fmt.Errorf("no default emulator in %v", names)
+	}
+	return c.Account(e.ServiceAccount)
+}
@@ -0,0 +1,139 @@
+package flowconfig
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/onflow/flow-go-sdk"
+	"github.com/onflow/flow-go-sdk/crypto"
+)
+
+const testConfig = `{
+	"emulators": {"default": {"port": 3569, "serviceAccount": "emulator-account"}},
+	"contracts": {
+		"ArenaToken": "./cadence/contracts/arenatoken.cdc",
+		"FungibleToken": {
+			"source": "./FungibleToken.cdc",
+			"aliases": {"emulator": "ee82856bf20e2aa6", "testnet": "0x9a0766d93b6608b7"}
+		}
+	},
+	"networks": {
+		"emulator": "127.0.0.1:3569",
+		"testnet": {"host": "access.devnet.nodes.onflow.org:9000", "chain": "flow-testnet"},
+		"staging": "127.0.0.1:3570"
+	},
+	"accounts": {
+		"emulator-account": {
+			"address": "f8d6e0586b0a20c7",
+			"key": "2eae2f31cb5b756151fa11d82949c634b8f28796a711d7eb1e52cc301ed11111"
+		},
+		"testnet-admin": {
+			"address": "0x0996b5100d5c8ad6",
+			"keys": [{
+				"type": "hex",
+				"index": 1,
+				"signatureAlgorithm": "ECDSA_secp256k1",
+				"hashAlgorithm": "SHA2_256",
+				"privateKey": "$ARENA_TESTNET_KEY"
+			}]
+		}
+	},
+	"deployments": {
+		"emulator": {"emulator-account": ["ArenaToken"]},
+		"testnet": {"testnet-admin": [{"name": "ArenaToken", "args": []}]}
+	}
+}`
+
+func TestAddresses(t *testing.T) {
+	cfg, err := Parse([]byte(testConfig))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	r, err := cfg.ArenaToken("testnet")
+	if err != nil {
+		t.Fatalf("ArenaToken: %v", err)
+	}
+	req, err := r.BuildSetupAccount()
+	if err != nil {
+		t.Fatalf("BuildSetupAccount: %v", err)
+	}
+	for _, imp := range []string{"import FungibleToken from 0x9a0766d93b6608b7", "import ArenaToken from 0x0996b5100d5c8ad6"} {
+		if !strings.Contains(string(req.Transaction.Script), imp) {
+			t.Fatalf("Expected %q in script:\n%s", imp, req.Transaction.Script)
+		}
+	}
+
+	contracts, err := cfg.Addresses("emulator")
+	if err != nil {
+		t.Fatalf("Addresses: %v", err)
+	}
+	if contracts["ArenaToken"] != flow.HexToAddress("f8d6e0586b0a20c7") {
+		t.Fatalf("Expected ArenaToken deployed to the emulator account, got: %v", contracts)
+	}
+
+	if _, err := cfg.Addresses("mainnet"); !errors.Is(err, ErrUnknownNetwork) {
+		t.Fatalf("Expected ErrUnknownNetwork, got: %v", err)
+	}
+	if chain, err := cfg.ChainID("testnet"); err != nil || chain != flow.Testnet {
+		t.Fatalf("ChainID: %v, %v", chain, err)
+	}
+	if _, err := cfg.ChainID("staging"); err == nil {
+		t.Fatal("Expected error for network without a chain")
+	}
+}
+
+func TestAccounts(t *testing.T) {
+	cfg, err := Parse([]byte(testConfig))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	acct, err := cfg.EmulatorServiceAccount()
+	if err != nil {
+		t.Fatalf("EmulatorServiceAccount: %v", err)
+	}
+	if len(acct.Keys) != 1 || acct.Keys[0].SigAlgo != crypto.ECDSA_P256 || acct.Keys[0].HashAlgo != crypto.SHA3_256 {
+		t.Fatalf("Unexpected emulator keys: %+v", acct.Keys)
+	}
+	if _, err := acct.Keys[0].Decode(); err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+
+	admin, err := cfg.Account("testnet-admin")
+	if err != nil {
+		t.Fatalf("Account: %v", err)
+	}
+	key := admin.Keys[0]
+	if key.Index != 1 || key.SigAlgo != crypto.ECDSA_secp256k1 || key.HashAlgo != crypto.SHA2_256 {
+		t.Fatalf("Unexpected testnet key: %+v", key)
+	}
+	// private keys are expanded from the environment
+	os.Setenv("ARENA_TESTNET_KEY", "a5d734436c43463019bc161294e22cad504d3d956a5d3e7f3a71989f83eaca44")
+	defer os.Unsetenv("ARENA_TESTNET_KEY")
+	if _, err := key.Decode(); err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+
+	if _, err := cfg.Account("nobody"); !errors.Is(err, ErrUnknownAccount) {
+		t.Fatalf("Expected ErrUnknownAccount, got: %v", err)
+	}
+}
+
+func TestRepositoryConfig(t *testing.T) {
+	path, err := Find()
+	if err != nil {
+		t.Fatalf("Find: %v", err)
+	}
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	for _, network := range []string{"emulator", "testnet"} {
+		if _, err := cfg.ArenaToken(network); err != nil {
+			t.Fatalf("ArenaToken(%s): %v", network, err)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package indexer
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go-sdk"
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	recordsBucket = []byte("records")
+	metaBucket    = []byte("meta")
+	checkpointKey = []byte("checkpoint")
+)
+
+// BoltSink is a Sink storing records in an embedded BoltDB file. Records are
+// keyed by height, transaction index and event index so rewriting a block after
+// a crash doesn't duplicate them.
+type BoltSink struct {
+	db *bolt.DB
+}
+
+// OpenBolt opens or creates the BoltDB file at path
+func OpenBolt(path string) (*BoltSink, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("opening %s: %w", path, err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{recordsBucket, metaBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("creating buckets in %s: %w", path, err)
+	}
+	return &BoltSink{db: db}, nil
+}
+
+// Close closes the database file
+func (s *BoltSink) Close() error {
+	return s.db.Close()
+}
+
+// Write stores the records and checkpoint in one transaction
+func (s *BoltSink) Write(_ context.Context, records []Record, height uint64) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(recordsBucket)
+		for _, r := range records {
+			value, err := json.Marshal(toStored(r))
+			if err != nil {
+				return err
+			}
+			if err := b.Put(recordKey(r), value); err != nil {
+				return err
+			}
+		}
+		return tx.Bucket(metaBucket).Put(checkpointKey, uint64Key(height))
+	})
+}
+
+// Checkpoint returns the height of the last Write
+func (s *BoltSink) Checkpoint(context.Context) (uint64, bool, error) {
+	var (
+		height uint64
+		ok     bool
+	)
+	err := s.db.View(func(tx *bolt.Tx) error {
+		if v := tx.Bucket(metaBucket).Get(checkpointKey); v != nil {
+			height, ok = binary.BigEndian.Uint64(v), true
+		}
+		return nil
+	})
+	return height, ok, err
+}
+
+// Records calls fn with the stored records of the blocks from start to end
+// inclusive, in the order they were emitted, until fn returns an error
+func (s *BoltSink) Records(start, end uint64, fn func(Record) error) error {
+	return s.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(recordsBucket).Cursor()
+		for k, v := c.Seek(uint64Key(start)); k != nil && binary.BigEndian.Uint64(k) <= end; k, v = c.Next() {
+			var stored storedRecord
+			if err := json.Unmarshal(v, &stored); err != nil {
+				return fmt.Errorf("decoding record %x: %w", k, err)
+			}
+			if err := fn(stored.record()); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// recordKey orders records by height, transaction index and event index
+func recordKey(r Record) []byte {
+	key := make([]byte, 16)
+	binary.BigEndian.PutUint64(key, r.Height)
+	binary.BigEndian.PutUint32(key[8:], uint32(r.TransactionIndex))
+	binary.BigEndian.PutUint32(key[12:], uint32(r.EventIndex))
+	return key
+}
+
+func uint64Key(v uint64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, v)
+	return key
+}
+
+// storedRecord is the json encoding of a record, identifiers as hex
+type storedRecord struct {
+	Height           uint64        `json:"height"`
+	BlockID          string        `json:"blockId"`
+	Timestamp        time.Time     `json:"timestamp"`
+	TransactionID    string        `json:"transactionId"`
+	TransactionIndex int           `json:"transactionIndex"`
+	EventIndex       int           `json:"eventIndex"`
+	Kind             string        `json:"kind"`
+	Amount           uint64        `json:"amount"`
+	Address          *flow.Address `json:"address,omitempty"`
+}
+
+func toStored(r Record) storedRecord {
+	return storedRecord{
+		Height:           r.Height,
+		BlockID:          r.BlockID.Hex(),
+		Timestamp:        r.Timestamp,
+		TransactionID:    r.TransactionID.Hex(),
+		TransactionIndex: r.TransactionIndex,
+		EventIndex:       r.EventIndex,
+		Kind:             r.Kind,
+		Amount:           uint64(r.Amount),
+		Address:          r.Address,
+	}
+}
+
+func (s storedRecord) record() Record {
+	return Record{
+		Height:           s.Height,
+		BlockID:          flow.HexToID(s.BlockID),
+		Timestamp:        s.Timestamp,
+		TransactionID:    flow.HexToID(s.TransactionID),
+		TransactionIndex: s.TransactionIndex,
+		EventIndex:       s.EventIndex,
+		Kind:             s.Kind,
+		Amount:           cadence.UFix64(s.Amount),
+		Address:          s.Address,
+	}
+}
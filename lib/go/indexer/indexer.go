@@ -0,0 +1,210 @@
+// Package indexer follows the events of a token contract over sealed block
+// ranges and writes them as normalized records to a Sink. Progress is
+// checkpointed with the records so an indexer resumes where it stopped.
+package indexer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/arena/arena-cadence/lib/go/events"
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go-sdk"
+	"github.com/onflow/flow-go-sdk/client"
+	"google.golang.org/grpc"
+)
+
+// DefaultWindow is the number of blocks queried at once, the most access nodes serve
+const DefaultWindow = 250
+
+// ErrStartHeight is returned when the start height is past the latest sealed block
+var ErrStartHeight = errors.New("start height is past latest sealed block")
+
+// Client is the subset of the flow access api used to read events
+type Client interface {
+	GetLatestBlockHeader(ctx context.Context, isSealed bool, opts ...grpc.CallOption) (*flow.BlockHeader, error)
+	GetEventsForHeightRange(ctx context.Context, query client.EventRangeQuery, opts ...grpc.CallOption) ([]client.BlockEvents, error)
+}
+
+// Record is a token contract event normalized for storage
+type Record struct {
+	Height           uint64
+	BlockID          flow.Identifier
+	Timestamp        time.Time
+	TransactionID    flow.Identifier
+	TransactionIndex int
+	EventIndex       int
+	// Kind is the name of the event, such as TokensDeposited
+	Kind string
+	// Amount is the amount of tokens of the event, zero if it has none
+	Amount cadence.UFix64
+	// Address is the account of a withdrawal, deposit or administrator event.
+	// It is nil for vaults not stored in an account and other events.
+	Address *flow.Address
+}
+
+// Sink stores records and the height they have been indexed up to
+type Sink interface {
+	// Write stores the records of the blocks up to and including height along
+	// with height as the checkpoint, atomically. Records are written in order.
+	Write(ctx context.Context, records []Record, height uint64) error
+	// Checkpoint returns the height of the last Write, false if there was none
+	Checkpoint(ctx context.Context) (uint64, bool, error)
+}
+
+// Indexer copies the events of a token contract to a Sink
+type Indexer struct {
+	Client  Client
+	Decoder *events.Decoder
+	Sink    Sink
+	// Start is the first height indexed into an empty sink, usually the height
+	// the contract was deployed at
+	Start uint64
+	// Events are the names of the events indexed, all events if empty
+	Events []string
+	// Window is the number of blocks queried at once, DefaultWindow if zero
+	Window uint64
+	// PollInterval is the delay between checks for new blocks in Run, 5s if zero
+	PollInterval time.Duration
+}
+
+// Run indexes up to the latest sealed block and then follows new blocks until
+// ctx is done
+func (ix *Indexer) Run(ctx context.Context) error {
+	interval := ix.PollInterval
+	if interval == 0 {
+		interval = 5 * time.Second
+	}
+	for {
+		if _, err := ix.Sync(ctx); err != nil && !errors.Is(err, ErrStartHeight) {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
+// Sync indexes the blocks after the checkpoint up to the latest sealed block,
+// one window at a time, and returns the new checkpoint
+func (ix *Indexer) Sync(ctx context.Context) (uint64, error) {
+	next := ix.Start
+	checkpoint, ok, err := ix.Sink.Checkpoint(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("reading checkpoint: %w", err)
+	}
+	if ok {
+		next = checkpoint + 1
+	}
+
+	latest, err := ix.Client.GetLatestBlockHeader(ctx, true)
+	if err != nil {
+		return 0, fmt.Errorf("getting latest block: %w", err)
+	}
+	if next > latest.Height {
+		if ok {
+			return checkpoint, nil
+		}
+		return 0, fmt.Errorf("%w: %d > %d", ErrStartHeight, next, latest.Height)
+	}
+
+	window := ix.Window
+	if window == 0 {
+		window = DefaultWindow
+	}
+	for next <= latest.Height {
+		end := next + window - 1
+		if end > latest.Height {
+			end = latest.Height
+		}
+		records, err := ix.records(ctx, next, end)
+		if err != nil {
+			return 0, err
+		}
+		if err := ix.Sink.Write(ctx, records, end); err != nil {
+			return 0, fmt.Errorf("writing blocks %d-%d: %w", next, end, err)
+		}
+		checkpoint, next = end, end+1
+	}
+	return checkpoint, nil
+}
+
+// records returns the records of the blocks from start to end, in the order
+// the events were emitted
+func (ix *Indexer) records(ctx context.Context, start, end uint64) ([]Record, error) {
+	types := ix.Decoder.TypeIDs()
+	if len(ix.Events) > 0 {
+		types = types[:0]
+		for _, name := range ix.Events {
+			types = append(types, ix.Decoder.TypeID(name))
+		}
+	}
+
+	var records []Record
+	for _, typ := range types {
+		blocks, err := ix.Client.GetEventsForHeightRange(ctx, client.EventRangeQuery{Type: typ, StartHeight: start, EndHeight: end})
+		if err != nil {
+			return nil, fmt.Errorf("getting %s events for blocks %d-%d: %w", typ, start, end, err)
+		}
+		for _, block := range blocks {
+			for _, e := range block.Events {
+				ev, err := ix.Decoder.Decode(e)
+				if err != nil {
+					return nil, fmt.Errorf("block %d: %w", block.Height, err)
+				}
+				r := normalize(ev)
+				r.Height, r.BlockID, r.Timestamp = block.Height, block.BlockID, block.BlockTimestamp
+				records = append(records, r)
+			}
+		}
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		a, b := records[i], records[j]
+		if a.Height != b.Height {
+			return a.Height < b.Height
+		}
+		if a.TransactionIndex != b.TransactionIndex {
+			return a.TransactionIndex < b.TransactionIndex
+		}
+		return a.EventIndex < b.EventIndex
+	})
+	return records, nil
+}
+
+// normalize returns the record of an event without its block fields
+func normalize(ev events.Event) Record {
+	meta := ev.Metadata()
+	r := Record{
+		TransactionID:    meta.TransactionID,
+		TransactionIndex: meta.TransactionIndex,
+		EventIndex:       meta.EventIndex,
+		Kind:             ev.Name(),
+	}
+	switch ev := ev.(type) {
+	case *events.TokensInitialized:
+		r.Amount = ev.InitialSupply
+	case *events.TokensWithdrawn:
+		r.Amount, r.Address = ev.Amount, ev.From
+	case *events.TokensDeposited:
+		r.Amount, r.Address = ev.Amount, ev.To
+	case *events.TokensMinted:
+		r.Amount = ev.Amount
+	case *events.TokensBurned:
+		r.Amount = ev.Amount
+	case *events.MinterCreated:
+		r.Amount = ev.AllowedAmount
+	case *events.BatchRecipientSkipped:
+		r.Amount, r.Address = ev.Amount, &ev.To
+	case *events.AdministratorOffered:
+		r.Address = &ev.Recipient
+	case *events.AdministratorClaimed:
+		r.Address = &ev.Recipient
+	}
+	return r
+}
@@ -0,0 +1,133 @@
+package indexer
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/arena/arena-cadence/lib/go/events"
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go-sdk"
+	"github.com/onflow/flow-go-sdk/client"
+	"google.golang.org/grpc"
+)
+
+var (
+	tokenAddr = flow.HexToAddress("0x0996b5100d5c8ad6")
+	user      = flow.HexToAddress("0x01cf0e2f2f715450")
+)
+
+// fakeClient serves events keyed by type id and height up to a latest height
+type fakeClient struct {
+	latest  uint64
+	events  map[string]map[uint64][]flow.Event
+	queries []client.EventRangeQuery
+}
+
+func (c *fakeClient) GetLatestBlockHeader(context.Context, bool, ...grpc.CallOption) (*flow.BlockHeader, error) {
+	return &flow.BlockHeader{Height: c.latest}, nil
+}
+
+func (c *fakeClient) GetEventsForHeightRange(_ context.Context, q client.EventRangeQuery, _ ...grpc.CallOption) ([]client.BlockEvents, error) {
+	if q.EndHeight > c.latest {
+		return nil, errors.New("end height not sealed")
+	}
+	c.queries = append(c.queries, q)
+	var blocks []client.BlockEvents
+	for h := q.StartHeight; h <= q.EndHeight; h++ {
+		blocks = append(blocks, client.BlockEvents{Height: h, BlockID: flow.Identifier{byte(h)}, Events: c.events[q.Type][h]})
+	}
+	return blocks, nil
+}
+
+// add emits an event with the given fields at height
+func (c *fakeClient) add(d *events.Decoder, height uint64, txIndex, eventIndex int, name string, fields []cadence.Field, values ...cadence.Value) {
+	typ := d.TypeID(name)
+	if c.events[typ] == nil {
+		c.events[typ] = make(map[uint64][]flow.Event)
+	}
+	c.events[typ][height] = append(c.events[typ][height], flow.Event{
+		Type:             typ,
+		TransactionID:    flow.Identifier{byte(height), byte(txIndex)},
+		TransactionIndex: txIndex,
+		EventIndex:       eventIndex,
+		Value:            cadence.NewEvent(values).WithType(&cadence.EventType{Fields: fields}),
+	})
+}
+
+var (
+	amountField = cadence.Field{Identifier: "amount", Type: cadence.UFix64Type{}}
+	toField     = cadence.Field{Identifier: "to", Type: cadence.OptionalType{Type: cadence.AddressType{}}}
+)
+
+func TestIndexer(t *testing.T) {
+	ctx := context.Background()
+	d := events.NewDecoder(tokenAddr, "")
+	c := &fakeClient{latest: 12, events: make(map[string]map[uint64][]flow.Event)}
+	amount, _ := cadence.NewUFix64("5.0")
+	c.add(d, 4, 0, 1, "TokensDeposited", []cadence.Field{amountField, toField}, amount, cadence.NewOptional(cadence.NewAddress(user)))
+	c.add(d, 4, 0, 0, "TokensMinted", []cadence.Field{amountField}, amount)
+	c.add(d, 11, 2, 0, "TokensBurned", []cadence.Field{amountField}, amount)
+
+	path := filepath.Join(t.TempDir(), "index.db")
+	sink, err := OpenBolt(path)
+	if err != nil {
+		t.Fatalf("OpenBolt: %v", err)
+	}
+	ix := &Indexer{Client: c, Decoder: d, Sink: sink, Start: 3, Window: 5, Events: []string{"TokensMinted", "TokensDeposited", "TokensBurned"}}
+	if height, err := ix.Sync(ctx); err != nil || height != 12 {
+		t.Fatalf("Expected sync to 12, got: %d %v", height, err)
+	}
+	// windows 3-7, 8-12 for each of 3 events
+	if len(c.queries) != 6 || c.queries[0].StartHeight != 3 || c.queries[5].EndHeight != 12 {
+		t.Fatalf("Unexpected queries: %+v", c.queries)
+	}
+
+	var kinds []string
+	err = sink.Records(0, 100, func(r Record) error {
+		kinds = append(kinds, r.Kind)
+		return nil
+	})
+	if err != nil || len(kinds) != 3 || kinds[0] != "TokensMinted" || kinds[1] != "TokensDeposited" || kinds[2] != "TokensBurned" {
+		t.Fatalf("Unexpected records %v: %v", kinds, err)
+	}
+	sink.Close()
+
+	// a restarted indexer resumes after the checkpoint
+	sink, err = OpenBolt(path)
+	if err != nil {
+		t.Fatalf("OpenBolt: %v", err)
+	}
+	defer sink.Close()
+	c.latest, c.queries = 14, nil
+	c.add(d, 13, 0, 0, "TokensDeposited", []cadence.Field{amountField, toField}, amount, cadence.NewOptional(nil))
+	ix.Sink = sink
+	if height, err := ix.Sync(ctx); err != nil || height != 14 || c.queries[0].StartHeight != 13 {
+		t.Fatalf("Expected sync from 13 to 14, got: %d %v %+v", height, err, c.queries)
+	}
+
+	var records []Record
+	sink.Records(13, 13, func(r Record) error {
+		records = append(records, r)
+		return nil
+	})
+	if len(records) != 1 || records[0].Address != nil || records[0].Amount != amount || records[0].BlockID != (flow.Identifier{13}) {
+		t.Fatalf("Unexpected records at 13: %+v", records)
+	}
+
+	// nothing new
+	if height, err := ix.Sync(ctx); err != nil || height != 14 {
+		t.Fatalf("Expected checkpoint 14, got: %d %v", height, err)
+	}
+
+	empty, err := OpenBolt(filepath.Join(t.TempDir(), "empty.db"))
+	if err != nil {
+		t.Fatalf("OpenBolt: %v", err)
+	}
+	defer empty.Close()
+	ix = &Indexer{Client: c, Decoder: d, Sink: empty, Start: 20}
+	if _, err := ix.Sync(ctx); !errors.Is(err, ErrStartHeight) {
+		t.Fatalf("Expected ErrStartHeight, got: %v", err)
+	}
+}
@@ -0,0 +1,291 @@
+// Package ledger reconstructs token movements and per address balances from the
+// indexed events of a token contract.
+//
+// Withdrawals and deposits are emitted separately, and either side is nil for
+// temporary vaults. Within a transaction, tokens withdrawn from an account or
+// minted are held as lots until deposited to an account or burned, and each
+// deposit or burn consumes the oldest lots first. This pairs the events into
+// transfer, mint and burn entries.
+package ledger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/arena/arena-cadence/lib/go/arenatoken"
+	"github.com/arena/arena-cadence/lib/go/indexer"
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go-sdk"
+	"google.golang.org/grpc"
+)
+
+// ErrOverdrawn is returned when a withdrawal exceeds the tracked balance of an
+// account, usually because the ledger wasn't seeded with its opening balance
+var ErrOverdrawn = errors.New("withdrawal exceeds tracked balance")
+
+// Kind is the kind of a ledger entry
+type Kind string
+
+const (
+	// KindInitial credits the initial supply to the contract account
+	KindInitial Kind = "initial"
+	// KindMint credits minted tokens to an account
+	KindMint Kind = "mint"
+	// KindBurn debits tokens burned from an account
+	KindBurn Kind = "burn"
+	// KindTransfer moves tokens between accounts
+	KindTransfer Kind = "transfer"
+	// KindUnmatched is a deposit of tokens that weren't withdrawn or minted in
+	// the same transaction
+	KindUnmatched Kind = "unmatched"
+	// KindUnsettled is tokens withdrawn or minted and neither deposited nor
+	// burned by the end of the transaction, such as a destroyed vault
+	KindUnsettled Kind = "unsettled"
+)
+
+// Entry is a movement of tokens. From is nil for mints and unmatched deposits,
+// To is nil for burns and unsettled tokens.
+type Entry struct {
+	Kind          Kind
+	Height        uint64
+	TransactionID flow.Identifier
+	From          *flow.Address
+	To            *flow.Address
+	Amount        cadence.UFix64
+}
+
+// Ledger keeps the running balances of the accounts holding tokens
+type Ledger struct {
+	contract flow.Address
+	balances map[flow.Address]cadence.UFix64
+	supply   cadence.UFix64
+	height   uint64
+}
+
+// New returns an empty ledger for the token contract deployed at contract, the
+// account credited with the initial supply
+func New(contract flow.Address) *Ledger {
+	return &Ledger{contract: contract, balances: make(map[flow.Address]cadence.UFix64)}
+}
+
+// Seed sets the opening balance of an account, for ledgers built from a height
+// after the contract was deployed
+func (l *Ledger) Seed(address flow.Address, balance cadence.UFix64) {
+	l.supply = l.supply - l.balances[address] + balance
+	l.balances[address] = balance
+}
+
+// Balance returns the tracked balance of an account
+func (l *Ledger) Balance(address flow.Address) cadence.UFix64 {
+	return l.balances[address]
+}
+
+// Balances returns a copy of the tracked balances
+func (l *Ledger) Balances() map[flow.Address]cadence.UFix64 {
+	out := make(map[flow.Address]cadence.UFix64, len(l.balances))
+	for addr, bal := range l.balances {
+		out[addr] = bal
+	}
+	return out
+}
+
+// Supply returns the tracked total supply, the sum of the tracked balances
+// between transactions
+func (l *Ledger) Supply() cadence.UFix64 {
+	return l.supply
+}
+
+// Height returns the height of the last applied record
+func (l *Ledger) Height() uint64 {
+	return l.height
+}
+
+// lot is an amount of tokens in a temporary vault. Source is nil for minted tokens.
+type lot struct {
+	source *flow.Address
+	amount cadence.UFix64
+}
+
+// Apply updates the balances with records in emitted order and returns their
+// entries. Records must hold every event of the transactions they include.
+func (l *Ledger) Apply(records []indexer.Record) ([]Entry, error) {
+	var entries []Entry
+	for start := 0; start < len(records); {
+		end := start + 1
+		for end < len(records) && records[end].TransactionID == records[start].TransactionID {
+			end++
+		}
+		txEntries, err := l.applyTransaction(records[start:end])
+		if err != nil {
+			return entries, err
+		}
+		entries = append(entries, txEntries...)
+		start = end
+	}
+	return entries, nil
+}
+
+// applyTransaction pairs the records of one transaction. Its balance and supply
+// changes are kept apart and only applied once every record has been paired, so
+// a failing transaction leaves the ledger as it was.
+func (l *Ledger) applyTransaction(records []indexer.Record) ([]Entry, error) {
+	var (
+		entries []Entry
+		lots    []lot
+		first   = records[0]
+		last    = records[len(records)-1]
+		supply  = l.supply
+		changed = make(map[flow.Address]cadence.UFix64)
+	)
+	balance := func(address flow.Address) cadence.UFix64 {
+		if bal, ok := changed[address]; ok {
+			return bal
+		}
+		return l.balances[address]
+	}
+	entry := func(kind Kind, from, to *flow.Address, amount cadence.UFix64) {
+		entries = append(entries, Entry{Kind: kind, Height: first.Height, TransactionID: first.TransactionID, From: from, To: to, Amount: amount})
+	}
+	// take consumes amount from the oldest lots, calling fn for each part
+	take := func(amount cadence.UFix64, fn func(source *flow.Address, amount cadence.UFix64)) cadence.UFix64 {
+		for amount > 0 && len(lots) > 0 {
+			part := lots[0].amount
+			if part > amount {
+				part = amount
+			}
+			fn(lots[0].source, part)
+			lots[0].amount -= part
+			amount -= part
+			if lots[0].amount == 0 {
+				lots = lots[1:]
+			}
+		}
+		return amount
+	}
+
+	for _, r := range records {
+		switch r.Kind {
+		case "TokensInitialized":
+			contract := l.contract
+			changed[contract] = balance(contract) + r.Amount
+			supply += r.Amount
+			entry(KindInitial, nil, &contract, r.Amount)
+
+		case "TokensMinted":
+			supply += r.Amount
+			lots = append(lots, lot{amount: r.Amount})
+
+		case "TokensWithdrawn":
+			// withdrawals from temporary vaults only split tokens already held
+			if r.Address == nil {
+				continue
+			}
+			if balance(*r.Address) < r.Amount {
+				return nil, fmt.Errorf("%w: %s withdrew %s with balance %s in transaction %s",
+					ErrOverdrawn, r.Address, r.Amount, balance(*r.Address), r.TransactionID)
+			}
+			changed[*r.Address] = balance(*r.Address) - r.Amount
+			lots = append(lots, lot{source: r.Address, amount: r.Amount})
+
+		case "TokensDeposited":
+			// deposits to temporary vaults only merge tokens already held
+			if r.Address == nil {
+				continue
+			}
+			changed[*r.Address] = balance(*r.Address) + r.Amount
+			to := r.Address
+			rest := take(r.Amount, func(source *flow.Address, amount cadence.UFix64) {
+				if source == nil {
+					entry(KindMint, nil, to, amount)
+				} else {
+					entry(KindTransfer, source, to, amount)
+				}
+			})
+			if rest > 0 {
+				supply += rest
+				entry(KindUnmatched, nil, to, rest)
+			}
+
+		case "TokensBurned":
+			rest := take(r.Amount, func(source *flow.Address, amount cadence.UFix64) {
+				entry(KindBurn, source, nil, amount)
+			})
+			// tokens burned that weren't withdrawn or minted were never tracked
+			supply -= r.Amount - rest
+			if rest > 0 {
+				entry(KindBurn, nil, nil, rest)
+			}
+		}
+	}
+
+	// tokens left in temporary vaults were destroyed or stored without a deposit
+	for _, lot := range lots {
+		supply -= lot.amount
+		entry(KindUnsettled, lot.source, nil, lot.amount)
+	}
+
+	for address, bal := range changed {
+		l.balances[address] = bal
+	}
+	l.supply = supply
+	l.height = last.Height
+	return entries, nil
+}
+
+// BalanceFunc returns the balance of an account on chain
+type BalanceFunc func(ctx context.Context, address flow.Address) (cadence.UFix64, error)
+
+// Divergence is an account whose tracked balance differs from chain
+type Divergence struct {
+	Address flow.Address
+	Ledger  cadence.UFix64
+	Chain   cadence.UFix64
+}
+
+// Check compares every tracked balance with chain and returns those that diverge,
+// ordered by address. Balances should be read at the height of the ledger.
+func (l *Ledger) Check(ctx context.Context, balanceOf BalanceFunc) ([]Divergence, error) {
+	addresses := make([]flow.Address, 0, len(l.balances))
+	for addr := range l.balances {
+		addresses = append(addresses, addr)
+	}
+	sort.Slice(addresses, func(i, j int) bool { return addresses[i].Hex() < addresses[j].Hex() })
+
+	var divergences []Divergence
+	for _, addr := range addresses {
+		chain, err := balanceOf(ctx, addr)
+		if err != nil {
+			return nil, fmt.Errorf("balance of %s: %w", addr, err)
+		}
+		if chain != l.balances[addr] {
+			divergences = append(divergences, Divergence{Address: addr, Ledger: l.balances[addr], Chain: chain})
+		}
+	}
+	return divergences, nil
+}
+
+// ScriptClient is the subset of the flow access api used to read balances
+type ScriptClient interface {
+	ExecuteScriptAtBlockHeight(ctx context.Context, height uint64, script []byte, arguments []cadence.Value, opts ...grpc.CallOption) (cadence.Value, error)
+}
+
+// ScriptBalance returns a BalanceFunc executing the Balance script of r at height
+func ScriptBalance(client ScriptClient, r *arenatoken.ArenaToken, height uint64) BalanceFunc {
+	return func(ctx context.Context, address flow.Address) (cadence.UFix64, error) {
+		req, err := r.BuildBalance(address)
+		if err != nil {
+			return 0, err
+		}
+		value, err := client.ExecuteScriptAtBlockHeight(ctx, height, req.Script, req.Arguments)
+		if err != nil {
+			return 0, err
+		}
+		balance, ok := value.(cadence.UFix64)
+		if !ok {
+			return 0, fmt.Errorf("balance script returned %T", value)
+		}
+		return balance, nil
+	}
+}
@@ -0,0 +1,126 @@
+package ledger
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/arena/arena-cadence/lib/go/indexer"
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go-sdk"
+)
+
+var (
+	contract = flow.HexToAddress("01")
+	alice    = flow.HexToAddress("02")
+	bob      = flow.HexToAddress("03")
+)
+
+func ufix(t *testing.T, s string) cadence.UFix64 {
+	t.Helper()
+	v, err := cadence.NewUFix64(s)
+	if err != nil {
+		t.Fatalf("NewUFix64: %v", err)
+	}
+	return v
+}
+
+// tx returns the records of a transaction from kind, amount, address triples
+func tx(t *testing.T, id byte, events ...interface{}) []indexer.Record {
+	var records []indexer.Record
+	for i := 0; i < len(events); i += 3 {
+		r := indexer.Record{
+			Height:        uint64(id),
+			TransactionID: flow.Identifier{id},
+			EventIndex:    i / 3,
+			Kind:          events[i].(string),
+			Amount:        ufix(t, events[i+1].(string)),
+		}
+		if addr, ok := events[i+2].(flow.Address); ok {
+			r.Address = &addr
+		}
+		records = append(records, r)
+	}
+	return records
+}
+
+func TestLedger(t *testing.T) {
+	var records []indexer.Record
+	for _, r := range [][]indexer.Record{
+		tx(t, 1, "TokensInitialized", "1000.0", nil),
+		tx(t, 2, "MinterCreated", "100.0", nil, "TokensMinted", "100.0", nil, "TokensDeposited", "100.0", alice),
+		tx(t, 3, "TokensWithdrawn", "30.0", alice, "TokensDeposited", "30.0", bob),
+		tx(t, 4, "TokensWithdrawn", "50.0", contract, "TokensBurned", "50.0", nil),
+		// a withdrawal split between two recipients
+		tx(t, 5, "TokensWithdrawn", "20.0", alice, "TokensWithdrawn", "5.0", nil,
+			"TokensDeposited", "15.0", bob, "TokensDeposited", "5.0", contract),
+		tx(t, 6, "TokensWithdrawn", "1.0", bob),
+	} {
+		records = append(records, r...)
+	}
+
+	l := New(contract)
+	entries, err := l.Apply(records)
+	if err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+
+	expected := []struct {
+		kind     Kind
+		from, to *flow.Address
+		amount   string
+	}{
+		{KindInitial, nil, &contract, "1000.0"},
+		{KindMint, nil, &alice, "100.0"},
+		{KindTransfer, &alice, &bob, "30.0"},
+		{KindBurn, &contract, nil, "50.0"},
+		{KindTransfer, &alice, &bob, "15.0"},
+		{KindTransfer, &alice, &contract, "5.0"},
+		{KindUnsettled, &bob, nil, "1.0"},
+	}
+	if len(entries) != len(expected) {
+		t.Fatalf("Expected %d entries, got: %+v", len(expected), entries)
+	}
+	same := func(a, b *flow.Address) bool {
+		return (a == nil && b == nil) || (a != nil && b != nil && *a == *b)
+	}
+	for i, e := range expected {
+		got := entries[i]
+		if got.Kind != e.kind || !same(got.From, e.from) || !same(got.To, e.to) || got.Amount != ufix(t, e.amount) {
+			t.Fatalf("Entry %d: expected %+v, got: %+v", i, e, got)
+		}
+	}
+
+	for addr, bal := range map[flow.Address]string{contract: "955.0", alice: "50.0", bob: "44.0"} {
+		if l.Balance(addr) != ufix(t, bal) {
+			t.Fatalf("Expected balance of %s %s, got: %s", addr, bal, l.Balance(addr))
+		}
+	}
+	if l.Supply() != ufix(t, "1049.0") || l.Height() != 6 {
+		t.Fatalf("Unexpected supply %s or height %d", l.Supply(), l.Height())
+	}
+
+	// bob's stored vault holds the unsettled token on chain
+	chain := map[flow.Address]cadence.UFix64{contract: ufix(t, "955.0"), alice: ufix(t, "50.0"), bob: ufix(t, "45.0")}
+	divergences, err := l.Check(context.Background(), func(_ context.Context, addr flow.Address) (cadence.UFix64, error) {
+		return chain[addr], nil
+	})
+	if err != nil || len(divergences) != 1 || divergences[0].Address != bob || divergences[0].Chain != ufix(t, "45.0") {
+		t.Fatalf("Expected bob to diverge, got: %+v %v", divergences, err)
+	}
+
+	if _, err := l.Apply(tx(t, 7, "TokensWithdrawn", "60.0", alice)); !errors.Is(err, ErrOverdrawn) {
+		t.Fatalf("Expected ErrOverdrawn, got: %v", err)
+	}
+
+	// a transaction overdrawn after its first records leaves the ledger unchanged
+	overdrawn := tx(t, 8, "TokensWithdrawn", "10.0", alice, "TokensDeposited", "10.0", bob, "TokensWithdrawn", "45.0", alice)
+	entries, err = l.Apply(overdrawn)
+	if !errors.Is(err, ErrOverdrawn) || len(entries) != 0 {
+		t.Fatalf("Expected ErrOverdrawn without entries, got: %+v %v", entries, err)
+	}
+	if l.Balance(alice) != ufix(t, "50.0") || l.Balance(bob) != ufix(t, "44.0") || l.Supply() != ufix(t, "1049.0") || l.Height() != 6 {
+		t.Fatalf("Expected the ledger unchanged, got alice %s bob %s supply %s height %d",
+			l.Balance(alice), l.Balance(bob), l.Supply(), l.Height())
+	}
+}
@@ -0,0 +1,467 @@
+package signing
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/arena/arena-cadence/lib/go/arenatoken"
+	jsoncdc "github.com/onflow/cadence/encoding/json"
+	"github.com/onflow/flow-go-sdk"
+	"google.golang.org/grpc"
+)
+
+var (
+	// ErrNotSigner is returned when signing an envelope for an account that has
+	// no role in the transaction
+	ErrNotSigner = errors.New("account is not a signer of the transaction")
+	// ErrPayloadIncomplete is returned when the payer signs before every payload
+	// signer has, since the envelope signature covers the payload signatures
+	ErrPayloadIncomplete = errors.New("payload signatures are incomplete")
+	// ErrEnvelopeIncomplete is returned when submitting a transaction the payer
+	// hasn't signed yet
+	ErrEnvelopeIncomplete = errors.New("envelope signature is missing")
+	// ErrEnvelopeSigned is returned when adding a payload signature to a
+	// transaction the payer has already signed
+	ErrEnvelopeSigned = errors.New("envelope is already signed")
+	// ErrEnvelopeMismatch is returned when an imported envelope doesn't
+	// reproduce the transaction ID it was exported with
+	ErrEnvelopeMismatch = errors.New("envelope does not match its transaction id")
+)
+
+// Envelope is a transaction passed between parties for signing offline. It is
+// exported either as the hex of the RLP encoded transaction or as JSON with the
+// arguments decoded, and either form can be read back with ReadEnvelope.
+//
+// A typical workflow prepares the transaction online with Prepare, exports it,
+// has each payload signer and then the payer add their signatures with Sign on
+// their own machines, and finally submits it with Submit.
+type Envelope struct {
+	Transaction *flow.Transaction
+	// Template is the path of the template the transaction was built from, if known
+	Template string
+	// Signature names the arguments of the transaction, if known
+	arenatoken.Signature
+}
+
+// NewEnvelope returns an envelope for a transaction built by ArenaToken
+func NewEnvelope(req *arenatoken.TransactionRequest) *Envelope {
+	return &Envelope{Transaction: req.Transaction, Template: req.Template, Signature: req.Signature}
+}
+
+// ReadEnvelope reads an envelope exported by Hex or JSON. The argument names of
+// hex envelopes are recovered from the transaction script.
+func ReadEnvelope(data []byte) (*Envelope, error) {
+	data = bytes.TrimSpace(data)
+	if bytes.HasPrefix(data, []byte("{")) {
+		var e Envelope
+		if err := json.Unmarshal(data, &e); err != nil {
+			return nil, err
+		}
+		return &e, nil
+	}
+
+	encoded, err := hex.DecodeString(strings.TrimPrefix(string(data), "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("decoding envelope hex: %w", err)
+	}
+	tx, err := flow.DecodeTransaction(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("decoding envelope transaction: %w", err)
+	}
+	// argument names are only informative, a script that doesn't parse is
+	// still signed and submitted as is
+	sig, _ := arenatoken.ParseSignature(tx.Script)
+	return &Envelope{Transaction: tx, Signature: sig}, nil
+}
+
+// Hex returns the hex of the RLP encoded transaction, signatures included
+func (e *Envelope) Hex() string {
+	return hex.EncodeToString(e.Transaction.Encode())
+}
+
+// Sign adds signatures of address with the provided keys, to the payload for the
+// proposer and authorizers or to the envelope for the payer. Keys are not
+// checked against the chain, see Signer for that.
+func (e *Envelope) Sign(address flow.Address, keys ...Key) error {
+	tx := e.Transaction
+	if len(keys) == 0 {
+		return fmt.Errorf("%w: %s", ErrNoKey, address)
+	}
+
+	if address == tx.Payer {
+		if missing := e.missingPayload(); len(missing) > 0 {
+			return fmt.Errorf("%w: waiting for %v", ErrPayloadIncomplete, missing)
+		}
+		for _, key := range keys {
+			if err := tx.SignEnvelope(address, key.Index, key.Signer); err != nil {
+				return fmt.Errorf("signing envelope as %s: %w", address, err)
+			}
+		}
+		return nil
+	}
+
+	if !containsAddress(PayloadSigners(tx), address) {
+		return fmt.Errorf("%w: %s", ErrNotSigner, address)
+	}
+	if len(tx.EnvelopeSignatures) > 0 {
+		return ErrEnvelopeSigned
+	}
+	for _, key := range keys {
+		if err := tx.SignPayload(address, key.Index, key.Signer); err != nil {
+			return fmt.Errorf("signing payload as %s: %w", address, err)
+		}
+	}
+	return nil
+}
+
+// Missing returns the accounts that have not signed yet, payload signers first
+// and then the payer. Key weights are not known offline, so an account counts as
+// signed once any of its keys has signed, or once its proposal key has for a
+// proposer that is neither payer nor authorizer. Submit checks the signed weights.
+func (e *Envelope) Missing() []flow.Address {
+	missing := e.missingPayload()
+	if len(e.Transaction.EnvelopeSignatures) == 0 {
+		missing = append(missing, e.Transaction.Payer)
+	}
+	return missing
+}
+
+func (e *Envelope) missingPayload() []flow.Address {
+	var missing []flow.Address
+	tx := e.Transaction
+	for _, address := range PayloadSigners(tx) {
+		keyIndex := -1
+		if proposesOnly(tx, address) {
+			keyIndex = tx.ProposalKey.KeyIndex
+		}
+		if !hasSignature(tx.PayloadSignatures, address, keyIndex) {
+			missing = append(missing, address)
+		}
+	}
+	return missing
+}
+
+// proposesOnly reports whether address is the proposer of tx and neither its
+// payer nor an authorizer, so that only its proposal key needs to sign
+func proposesOnly(tx *flow.Transaction, address flow.Address) bool {
+	return address == tx.ProposalKey.Address && address != tx.Payer && !containsAddress(tx.Authorizers, address)
+}
+
+// Summary returns a human readable description of the transaction and the
+// signatures it holds
+func (e *Envelope) Summary() string {
+	tx := e.Transaction
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Transaction %s\n", tx.ID())
+	if e.Template != "" {
+		fmt.Fprintf(&b, "Template:        %s\n", e.Template)
+	}
+	fmt.Fprintf(&b, "Reference block: %s\n", tx.ReferenceBlockID)
+	fmt.Fprintf(&b, "Gas limit:       %d\n", tx.GasLimit)
+	fmt.Fprintf(&b, "Proposer:        %s key %d sequence %d\n", "0x"+tx.ProposalKey.Address.Hex(), tx.ProposalKey.KeyIndex, tx.ProposalKey.SequenceNumber)
+	fmt.Fprintf(&b, "Payer:           %s\n", "0x"+tx.Payer.Hex())
+	for i, authorizer := range tx.Authorizers {
+		name := ""
+		if i < len(e.Authorizers) {
+			name = " (" + e.Authorizers[i] + ")"
+		}
+		fmt.Fprintf(&b, "Authorizer:      %s%s\n", "0x"+authorizer.Hex(), name)
+	}
+
+	b.WriteString("Arguments:\n")
+	for i := range tx.Arguments {
+		name := fmt.Sprintf("%d", i)
+		if i < len(e.Params) {
+			name = e.Params[i].Name
+		}
+		value, err := tx.Argument(i)
+		if err != nil {
+			fmt.Fprintf(&b, "  %s: undecodable: %v\n", name, err)
+			continue
+		}
+		fmt.Fprintf(&b, "  %s: %s = %s\n", name, value.Type().ID(), value)
+	}
+
+	b.WriteString("Signatures:\n")
+	for _, sig := range tx.PayloadSignatures {
+		fmt.Fprintf(&b, "  payload  %s key %d\n", "0x"+sig.Address.Hex(), sig.KeyIndex)
+	}
+	for _, sig := range tx.EnvelopeSignatures {
+		fmt.Fprintf(&b, "  envelope %s key %d\n", "0x"+sig.Address.Hex(), sig.KeyIndex)
+	}
+	for _, address := range e.Missing() {
+		fmt.Fprintf(&b, "  missing  %s\n", "0x"+address.Hex())
+	}
+
+	return b.String()
+}
+
+// Sender is the subset of the flow access api used to submit envelopes
+type Sender interface {
+	GetAccount(ctx context.Context, address flow.Address, opts ...grpc.CallOption) (*flow.Account, error)
+	SendTransaction(ctx context.Context, tx flow.Transaction, opts ...grpc.CallOption) error
+}
+
+// Submit sends the transaction once the payer and authorizers have signed with
+// keys whose weight on chain reaches flow.AccountKeyWeightThreshold, and the
+// proposal key has signed
+func (e *Envelope) Submit(ctx context.Context, client Sender) error {
+	if missing := e.missingPayload(); len(missing) > 0 {
+		return fmt.Errorf("%w: waiting for %v", ErrPayloadIncomplete, missing)
+	}
+	if len(e.Transaction.EnvelopeSignatures) == 0 {
+		return fmt.Errorf("%w: waiting for %s", ErrEnvelopeIncomplete, e.Transaction.Payer)
+	}
+
+	for _, address := range PayloadSigners(e.Transaction) {
+		if proposesOnly(e.Transaction, address) {
+			if err := proposalKeyActive(ctx, client, e.Transaction.ProposalKey); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := signedWeight(ctx, client, address, e.Transaction.PayloadSignatures); err != nil {
+			return err
+		}
+	}
+	if err := signedWeight(ctx, client, e.Transaction.Payer, e.Transaction.EnvelopeSignatures); err != nil {
+		return err
+	}
+	return client.SendTransaction(ctx, *e.Transaction)
+}
+
+// signedWeight checks that the unrevoked keys of address that signed sigs reach
+// flow.AccountKeyWeightThreshold, returning a WeightError otherwise
+func signedWeight(ctx context.Context, client Sender, address flow.Address, sigs []flow.TransactionSignature) error {
+	account, err := client.GetAccount(ctx, address)
+	if err != nil {
+		return fmt.Errorf("getting account %s: %w", address, err)
+	}
+
+	weightErr := &WeightError{Address: address}
+	counted := make(map[int]bool)
+	for _, sig := range sigs {
+		if sig.Address != address || counted[sig.KeyIndex] {
+			continue
+		}
+		counted[sig.KeyIndex] = true
+		key, err := accountKey(account, sig.KeyIndex)
+		if err != nil {
+			weightErr.Rejected = append(weightErr.Rejected, fmt.Errorf("%w: %s key %d", ErrNoKey, address, sig.KeyIndex))
+			continue
+		}
+		if key.Revoked {
+			weightErr.Rejected = append(weightErr.Rejected, fmt.Errorf("%w: %s key %d", ErrRevokedKey, address, sig.KeyIndex))
+			continue
+		}
+		weightErr.Weight += key.Weight
+	}
+	if weightErr.Weight < flow.AccountKeyWeightThreshold {
+		return weightErr
+	}
+	return nil
+}
+
+// proposalKeyActive checks that the proposal key exists on chain and isn't revoked
+func proposalKeyActive(ctx context.Context, client Sender, proposalKey flow.ProposalKey) error {
+	account, err := client.GetAccount(ctx, proposalKey.Address)
+	if err != nil {
+		return fmt.Errorf("getting account %s: %w", proposalKey.Address, err)
+	}
+	key, err := accountKey(account, proposalKey.KeyIndex)
+	if err != nil {
+		return fmt.Errorf("%w: %s key %d", ErrNoKey, proposalKey.Address, proposalKey.KeyIndex)
+	}
+	if key.Revoked {
+		return fmt.Errorf("%w: %s key %d", ErrRevokedKey, proposalKey.Address, proposalKey.KeyIndex)
+	}
+	return nil
+}
+
+// envelopeJSON is the JSON form of an Envelope
+type envelopeJSON struct {
+	ID                 string          `json:"id"`
+	Template           string          `json:"template,omitempty"`
+	Script             string          `json:"script"`
+	Arguments          []argumentJSON  `json:"arguments"`
+	ReferenceBlockID   string          `json:"referenceBlockId"`
+	GasLimit           uint64          `json:"gasLimit"`
+	ProposalKey        proposalKeyJSON `json:"proposalKey"`
+	Payer              string          `json:"payer"`
+	Authorizers        []string        `json:"authorizers"`
+	PayloadSignatures  []signatureJSON `json:"payloadSignatures"`
+	EnvelopeSignatures []signatureJSON `json:"envelopeSignatures"`
+}
+
+type argumentJSON struct {
+	Name string `json:"name,omitempty"`
+	Type string `json:"type,omitempty"`
+	// Value is the JSON-Cadence encoded argument, reformatted for reading
+	Value json.RawMessage `json:"value"`
+	// Raw is the hex of the argument bytes as the transaction holds them
+	Raw string `json:"raw,omitempty"`
+}
+
+type proposalKeyJSON struct {
+	Address        string `json:"address"`
+	KeyIndex       int    `json:"keyIndex"`
+	SequenceNumber uint64 `json:"sequenceNumber"`
+}
+
+type signatureJSON struct {
+	Address   string `json:"address"`
+	KeyIndex  int    `json:"keyIndex"`
+	Signature string `json:"signature"`
+}
+
+// MarshalJSON encodes the envelope with its arguments decoded, next to their raw bytes
+func (e *Envelope) MarshalJSON() ([]byte, error) {
+	tx := e.Transaction
+	out := envelopeJSON{
+		ID:               tx.ID().String(),
+		Template:         e.Template,
+		Script:           string(tx.Script),
+		ReferenceBlockID: tx.ReferenceBlockID.String(),
+		GasLimit:         tx.GasLimit,
+		ProposalKey: proposalKeyJSON{
+			Address:        tx.ProposalKey.Address.Hex(),
+			KeyIndex:       tx.ProposalKey.KeyIndex,
+			SequenceNumber: tx.ProposalKey.SequenceNumber,
+		},
+		Payer:              tx.Payer.Hex(),
+		Authorizers:        []string{},
+		PayloadSignatures:  signaturesJSON(tx.PayloadSignatures),
+		EnvelopeSignatures: signaturesJSON(tx.EnvelopeSignatures),
+	}
+	for i, arg := range tx.Arguments {
+		a := argumentJSON{Value: json.RawMessage(bytes.TrimSpace(arg)), Raw: hex.EncodeToString(arg)}
+		if i < len(e.Params) {
+			a.Name, a.Type = e.Params[i].Name, e.Params[i].Type
+		}
+		out.Arguments = append(out.Arguments, a)
+	}
+	for _, authorizer := range tx.Authorizers {
+		out.Authorizers = append(out.Authorizers, authorizer.Hex())
+	}
+	return json.MarshalIndent(out, "", "  ")
+}
+
+func signaturesJSON(sigs []flow.TransactionSignature) []signatureJSON {
+	out := []signatureJSON{}
+	for _, sig := range sigs {
+		out = append(out, signatureJSON{
+			Address:   sig.Address.Hex(),
+			KeyIndex:  sig.KeyIndex,
+			Signature: hex.EncodeToString(sig.Signature),
+		})
+	}
+	return out
+}
+
+// UnmarshalJSON decodes an envelope encoded by MarshalJSON. Arguments are
+// restored from their raw bytes, or taken as is from their value when those are
+// missing, and the resulting transaction ID must match the exported one. A value
+// that differs from its raw bytes is reported as ErrEnvelopeMismatch.
+func (e *Envelope) UnmarshalJSON(data []byte) error {
+	var in envelopeJSON
+	if err := json.Unmarshal(data, &in); err != nil {
+		return fmt.Errorf("decoding envelope json: %w", err)
+	}
+
+	tx := flow.NewTransaction().
+		SetScript([]byte(in.Script)).
+		SetReferenceBlockID(flow.HexToID(in.ReferenceBlockID)).
+		SetGasLimit(in.GasLimit).
+		SetProposalKey(flow.HexToAddress(in.ProposalKey.Address), in.ProposalKey.KeyIndex, in.ProposalKey.SequenceNumber).
+		SetPayer(flow.HexToAddress(in.Payer))
+	for _, authorizer := range in.Authorizers {
+		tx.AddAuthorizer(flow.HexToAddress(authorizer))
+	}
+
+	var sig arenatoken.Signature
+	for i, arg := range in.Arguments {
+		raw := []byte(arg.Value)
+		if arg.Raw != "" {
+			var err error
+			if raw, err = hex.DecodeString(arg.Raw); err != nil {
+				return fmt.Errorf("decoding argument %d: %w", i, err)
+			}
+			if err := sameArgument(raw, arg.Value); err != nil {
+				return fmt.Errorf("argument %d: %w", i, err)
+			}
+		}
+		tx.AddRawArgument(raw)
+		sig.Params = append(sig.Params, arenatoken.Param{Name: arg.Name, Type: arg.Type})
+	}
+
+	for _, s := range in.PayloadSignatures {
+		raw, err := hex.DecodeString(s.Signature)
+		if err != nil {
+			return fmt.Errorf("decoding payload signature of %s: %w", s.Address, err)
+		}
+		tx.AddPayloadSignature(flow.HexToAddress(s.Address), s.KeyIndex, raw)
+	}
+	for _, s := range in.EnvelopeSignatures {
+		raw, err := hex.DecodeString(s.Signature)
+		if err != nil {
+			return fmt.Errorf("decoding envelope signature of %s: %w", s.Address, err)
+		}
+		tx.AddEnvelopeSignature(flow.HexToAddress(s.Address), s.KeyIndex, raw)
+	}
+
+	if in.ID != "" && tx.ID().String() != in.ID {
+		return fmt.Errorf("%w: exported %s, imported %s", ErrEnvelopeMismatch, in.ID, tx.ID())
+	}
+
+	// authorizer names aren't exported, recover them from the script
+	if parsed, err := arenatoken.ParseSignature(tx.Script); err == nil {
+		sig.Authorizers = parsed.Authorizers
+	}
+
+	*e = Envelope{Transaction: tx, Template: in.Template, Signature: sig}
+	return nil
+}
+
+// sameArgument checks that the JSON-Cadence arguments raw and value decode to
+// the same value, so the readable value can't hide what is signed
+func sameArgument(raw []byte, value json.RawMessage) error {
+	signed, err := jsoncdc.Decode(raw)
+	if err != nil {
+		return fmt.Errorf("decoding raw argument: %w", err)
+	}
+	shown, err := jsoncdc.Decode(value)
+	if err != nil {
+		return fmt.Errorf("decoding argument value: %w", err)
+	}
+	if !reflect.DeepEqual(signed, shown) {
+		return fmt.Errorf("%w: value %s is encoded as %s", ErrEnvelopeMismatch, shown, signed)
+	}
+	return nil
+}
+
+// hasSignature reports whether sigs hold a signature of address, by the key at
+// keyIndex unless negative
+func hasSignature(sigs []flow.TransactionSignature, address flow.Address, keyIndex int) bool {
+	for _, sig := range sigs {
+		if sig.Address == address && (keyIndex < 0 || sig.KeyIndex == keyIndex) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsAddress(addresses []flow.Address, address flow.Address) bool {
+	for _, a := range addresses {
+		if a == address {
+			return true
+		}
+	}
+	return false
+}
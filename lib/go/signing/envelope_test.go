@@ -0,0 +1,206 @@
+package signing
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/arena/arena-cadence/lib/go/arenatoken"
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go-sdk"
+	"google.golang.org/grpc"
+)
+
+// fakeSender records the submitted transaction, reading accounts from fakeClient
+type fakeSender struct {
+	*fakeClient
+	sent *flow.Transaction
+}
+
+func (s *fakeSender) SendTransaction(_ context.Context, tx flow.Transaction, _ ...grpc.CallOption) error {
+	s.sent = &tx
+	return nil
+}
+
+func TestEnvelope(t *testing.T) {
+	client := &fakeClient{accounts: make(map[flow.Address]*flow.Account)}
+	keys := make(InMemory)
+	admin := flow.HexToAddress("02")
+	payer := flow.HexToAddress("01")
+	recipient := flow.HexToAddress("03")
+	for _, addr := range []flow.Address{payer, admin} {
+		newAccount(t, client, keys, addr)
+	}
+
+	r := arenatoken.New(admin, flow.HexToAddress("ee82856bf20e2aa6"))
+	amount, _ := cadence.NewUFix64("25.5")
+	req, err := r.BuildMintTokens(recipient, amount)
+	if err != nil {
+		t.Fatalf("BuildMintTokens: %v", err)
+	}
+	roles := Roles{Proposer: admin, Payer: payer, Authorizers: []flow.Address{admin}}
+	if err := Prepare(context.Background(), client, roles, 0, req.Transaction); err != nil {
+		t.Fatalf("Prepare: %v", err)
+	}
+
+	// export unsigned as json and import on the admin's machine
+	env := NewEnvelope(req)
+	data, err := env.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+	env, err = ReadEnvelope(data)
+	if err != nil {
+		t.Fatalf("ReadEnvelope: %v", err)
+	}
+	if env.Transaction.ID() != req.Transaction.ID() {
+		t.Fatalf("Imported transaction %s differs from %s", env.Transaction.ID(), req.Transaction.ID())
+	}
+	summary := env.Summary()
+	for _, line := range []string{"recipient: Address = 0x3", "amount: UFix64 = 25.50000000", "missing  0x" + admin.Hex()} {
+		if !strings.Contains(summary, line) {
+			t.Fatalf("Expected %q in summary:\n%s", line, summary)
+		}
+	}
+
+	// the payer can't sign before the admin
+	payerKeys, _ := keys.Keys(context.Background(), payer)
+	if err := env.Sign(payer, payerKeys...); !errors.Is(err, ErrPayloadIncomplete) {
+		t.Fatalf("Expected ErrPayloadIncomplete, got: %v", err)
+	}
+	if err := env.Sign(recipient, payerKeys...); !errors.Is(err, ErrNotSigner) {
+		t.Fatalf("Expected ErrNotSigner, got: %v", err)
+	}
+
+	adminKeys, _ := keys.Keys(context.Background(), admin)
+	if err := env.Sign(admin, adminKeys...); err != nil {
+		t.Fatalf("Sign as admin: %v", err)
+	}
+
+	// pass on as hex to the payer
+	env, err = ReadEnvelope([]byte(env.Hex()))
+	if err != nil {
+		t.Fatalf("ReadEnvelope hex: %v", err)
+	}
+	if len(env.Params) != 2 || env.Params[0].Name != "recipient" {
+		t.Fatalf("Expected params recovered from script, got: %v", env.Params)
+	}
+	sender := &fakeSender{fakeClient: client}
+	if err := env.Submit(context.Background(), sender); !errors.Is(err, ErrEnvelopeIncomplete) {
+		t.Fatalf("Expected ErrEnvelopeIncomplete, got: %v", err)
+	}
+	if err := env.Sign(payer, payerKeys...); err != nil {
+		t.Fatalf("Sign as payer: %v", err)
+	}
+	if err := env.Sign(admin, adminKeys...); !errors.Is(err, ErrEnvelopeSigned) {
+		t.Fatalf("Expected ErrEnvelopeSigned, got: %v", err)
+	}
+
+	// signatures survive a json round trip
+	data, err = env.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+	env, err = ReadEnvelope(data)
+	if err != nil {
+		t.Fatalf("ReadEnvelope: %v", err)
+	}
+
+	// a payer key under the weight threshold can't submit alone
+	client.accounts[payer].Keys[0].Weight = flow.AccountKeyWeightThreshold / 2
+	var weightErr *WeightError
+	if err := env.Submit(context.Background(), sender); !errors.As(err, &weightErr) || weightErr.Address != payer || sender.sent != nil {
+		t.Fatalf("Expected WeightError for the payer, got: %v", err)
+	}
+	client.accounts[payer].Keys[0].Weight = flow.AccountKeyWeightThreshold
+
+	if err := env.Submit(context.Background(), sender); err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+	if len(sender.sent.PayloadSignatures) != 1 || len(sender.sent.EnvelopeSignatures) != 1 {
+		t.Fatalf("Unexpected signatures: %v, %v", sender.sent.PayloadSignatures, sender.sent.EnvelopeSignatures)
+	}
+
+	// tampered arguments are detected
+	tampered := strings.Replace(string(data), "25.50000000", "95.50000000", 1)
+	if _, err := ReadEnvelope([]byte(tampered)); !errors.Is(err, ErrEnvelopeMismatch) {
+		t.Fatalf("Expected ErrEnvelopeMismatch, got: %v", err)
+	}
+}
+
+func TestEnvelopeRawArguments(t *testing.T) {
+	client := &fakeClient{accounts: make(map[flow.Address]*flow.Account)}
+	keys := make(InMemory)
+	payer := flow.HexToAddress("01")
+	newAccount(t, client, keys, payer)
+
+	// arguments encoded by other tools, with a different field order
+	tx := flow.NewTransaction().
+		SetScript([]byte("transaction(amount: UFix64) {}")).
+		AddRawArgument([]byte(`{"value":"25.50000000","type":"UFix64"}`))
+	if err := Prepare(context.Background(), client, Roles{Payer: payer}, 0, tx); err != nil {
+		t.Fatalf("Prepare: %v", err)
+	}
+	data, err := (&Envelope{Transaction: tx}).MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+	env, err := ReadEnvelope(data)
+	if err != nil {
+		t.Fatalf("ReadEnvelope: %v", err)
+	}
+	if env.Transaction.ID() != tx.ID() {
+		t.Fatalf("Imported transaction %s differs from %s", env.Transaction.ID(), tx.ID())
+	}
+	if summary := env.Summary(); !strings.Contains(summary, "UFix64 = 25.50000000") {
+		t.Fatalf("Expected the decoded amount in summary:\n%s", summary)
+	}
+}
+
+func TestEnvelopeProposer(t *testing.T) {
+	client := &fakeClient{accounts: make(map[flow.Address]*flow.Account)}
+	keys := make(InMemory)
+	payer := flow.HexToAddress("01")
+	proposer := flow.HexToAddress("07")
+	for _, addr := range []flow.Address{payer, proposer} {
+		newAccount(t, client, keys, addr)
+	}
+	client.accounts[proposer].Keys[0].Weight = 0
+
+	tx := flow.NewTransaction().SetScript([]byte("transaction {}"))
+	if err := Prepare(context.Background(), client, Roles{Proposer: proposer, Payer: payer}, 0, tx); err != nil {
+		t.Fatalf("Prepare: %v", err)
+	}
+	env := &Envelope{Transaction: tx}
+	proposerKeys, _ := keys.Keys(context.Background(), proposer)
+	payerKeys, _ := keys.Keys(context.Background(), payer)
+
+	// a signature by a key other than the proposal key doesn't count
+	otherKey := proposerKeys[0]
+	otherKey.Index = 1
+	if err := env.Sign(proposer, otherKey); err != nil {
+		t.Fatalf("Sign as proposer: %v", err)
+	}
+	if missing := env.Missing(); len(missing) != 2 || missing[0] != proposer {
+		t.Fatalf("Expected the proposer missing, got: %v", missing)
+	}
+
+	// the weight 0 proposal key is enough for a proposer that only proposes
+	env.Transaction.PayloadSignatures = nil
+	if err := env.Sign(proposer, proposerKeys...); err != nil {
+		t.Fatalf("Sign as proposer: %v", err)
+	}
+	if err := env.Sign(payer, payerKeys...); err != nil {
+		t.Fatalf("Sign as payer: %v", err)
+	}
+	sender := &fakeSender{fakeClient: client}
+	if err := env.Submit(context.Background(), sender); err != nil || sender.sent == nil {
+		t.Fatalf("Submit: %v", err)
+	}
+
+	client.accounts[proposer].Keys[0].Revoked = true
+	if err := env.Submit(context.Background(), sender); !errors.Is(err, ErrRevokedKey) {
+		t.Fatalf("Expected ErrRevokedKey, got: %v", err)
+	}
+}
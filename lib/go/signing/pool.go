@@ -0,0 +1,246 @@
+package signing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/onflow/flow-go-sdk"
+)
+
+var (
+	// ErrLeaseProposer is returned when signing with a lease for another proposer
+	ErrLeaseProposer = errors.New("proposer is not the lease account")
+	// ErrNoProposalKey is returned when a pool has no usable key
+	ErrNoProposalKey = errors.New("no usable proposal key")
+)
+
+// ProposerPool hands out the keys of a proposer account so that transactions can
+// be submitted in parallel without reusing a sequence number. Each key proposes
+// one transaction at a time, from its lease until Complete records the outcome,
+// so that a dropped transaction can't hold up later ones of the same key. Its
+// sequence number is tracked locally rather than read from chain for every
+// transaction, and as many transactions are in flight as the pool has keys.
+//
+// A key whose transaction failed is resynced from chain before it is leased again.
+type ProposerPool struct {
+	client  Client
+	address flow.Address
+
+	mu   sync.Mutex
+	keys []*poolKey
+	// freed is closed and replaced whenever a key may have become leasable
+	freed    chan struct{}
+	inFlight map[flow.Identifier]*poolKey
+}
+
+// poolKey is the local state of a proposal key
+type poolKey struct {
+	index    int
+	sequence uint64
+	leased   bool
+	inFlight int
+	// stale is set when the local sequence number may differ from chain
+	stale bool
+}
+
+// leasable reports whether the key can be leased, possibly after a resync
+func (k *poolKey) leasable() bool {
+	return !k.leased && k.inFlight == 0
+}
+
+// Lease is the exclusive use of a proposal key and sequence number. It must be
+// ended with Sent once the transaction is submitted or Release otherwise.
+type Lease struct {
+	Address        flow.Address
+	KeyIndex       int
+	SequenceNumber uint64
+
+	pool *ProposerPool
+	key  *poolKey
+	done bool
+}
+
+// NewProposerPool returns a pool of the keys at indices of the proposer account
+// at address, or all of its unrevoked keys if no index is given. The sequence
+// numbers are read from chain.
+func NewProposerPool(ctx context.Context, client Client, address flow.Address, indices ...int) (*ProposerPool, error) {
+	account, err := client.GetAccount(ctx, address)
+	if err != nil {
+		return nil, fmt.Errorf("getting account %s: %w", address, err)
+	}
+	if len(indices) == 0 {
+		for _, key := range account.Keys {
+			if !key.Revoked {
+				indices = append(indices, key.Index)
+			}
+		}
+	}
+	if len(indices) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNoProposalKey, address)
+	}
+
+	p := &ProposerPool{
+		client:   client,
+		address:  address,
+		freed:    make(chan struct{}),
+		inFlight: make(map[flow.Identifier]*poolKey),
+	}
+	for _, index := range indices {
+		proposalKey, err := proposalKeyOf(account, index)
+		if err != nil {
+			return nil, err
+		}
+		p.keys = append(p.keys, &poolKey{index: index, sequence: proposalKey.SequenceNumber})
+	}
+	return p, nil
+}
+
+// Address returns the proposer account of the pool
+func (p *ProposerPool) Address() flow.Address {
+	return p.address
+}
+
+// Acquire leases a free key, waiting until a lease is released or a transaction
+// completes, or ctx is done
+func (p *ProposerPool) Acquire(ctx context.Context) (*Lease, error) {
+	for {
+		p.mu.Lock()
+		key := p.nextKey()
+		if key == nil {
+			freed := p.freed
+			p.mu.Unlock()
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-freed:
+				continue
+			}
+		}
+		key.leased = true
+		stale := key.stale
+		p.mu.Unlock()
+
+		if stale {
+			if err := p.resync(ctx, key); err != nil {
+				p.mu.Lock()
+				key.leased = false
+				p.notify()
+				p.mu.Unlock()
+				return nil, err
+			}
+		}
+
+		p.mu.Lock()
+		lease := &Lease{Address: p.address, KeyIndex: key.index, SequenceNumber: key.sequence, pool: p, key: key}
+		p.mu.Unlock()
+		return lease, nil
+	}
+}
+
+// nextKey returns the first leasable key
+func (p *ProposerPool) nextKey() *poolKey {
+	for _, key := range p.keys {
+		if key.leasable() {
+			return key
+		}
+	}
+	return nil
+}
+
+// resync reads the sequence number of a leased key from chain
+func (p *ProposerPool) resync(ctx context.Context, key *poolKey) error {
+	account, err := p.client.GetAccount(ctx, p.address)
+	if err != nil {
+		return fmt.Errorf("getting account %s: %w", p.address, err)
+	}
+	proposalKey, err := proposalKeyOf(account, key.index)
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	key.sequence = proposalKey.SequenceNumber
+	key.stale = false
+	return nil
+}
+
+// Sent ends the lease after its transaction was submitted. The key proposes the
+// next sequence number once Complete records the outcome of the transaction.
+func (l *Lease) Sent(txID flow.Identifier) {
+	p := l.pool
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if l.done {
+		return
+	}
+	l.done = true
+
+	l.key.sequence++
+	l.key.inFlight++
+	l.key.leased = false
+	p.inFlight[txID] = l.key
+	p.notify()
+}
+
+// Release ends the lease without submitting a transaction. If submission was
+// attempted and failed the key is resynced before it is leased again.
+func (l *Lease) Release(err error) {
+	p := l.pool
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if l.done {
+		return
+	}
+	l.done = true
+
+	if err != nil {
+		l.key.stale = true
+	}
+	l.key.leased = false
+	p.notify()
+}
+
+// Complete records the outcome of a transaction sent with a lease. An error,
+// such as an expired or rejected transaction, marks its key for a resync since
+// the sequence number it proposed may not have been used. Unknown transactions
+// are ignored.
+func (p *ProposerPool) Complete(txID flow.Identifier, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	key, ok := p.inFlight[txID]
+	if !ok {
+		return
+	}
+	delete(p.inFlight, txID)
+	key.inFlight--
+	if err != nil {
+		key.stale = true
+	}
+	p.notify()
+}
+
+// Resync marks every key for a resync from chain before it is leased again
+func (p *ProposerPool) Resync() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, key := range p.keys {
+		key.stale = true
+	}
+}
+
+// InFlight returns the number of transactions sent and not yet completed
+func (p *ProposerPool) InFlight() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.inFlight)
+}
+
+// notify wakes goroutines waiting in Acquire, p.mu must be held
+func (p *ProposerPool) notify() {
+	close(p.freed)
+	p.freed = make(chan struct{})
+}
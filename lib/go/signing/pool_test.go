@@ -0,0 +1,161 @@
+package signing
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/onflow/flow-go-sdk"
+	"github.com/onflow/flow-go-sdk/crypto"
+)
+
+// newPoolAccount adds a proposer with weight 0 proposal keys at sequence numbers
+// 7 and 20, and a separate payer
+func newPoolAccount(t *testing.T) (*fakeClient, InMemory, flow.Address, flow.Address) {
+	t.Helper()
+
+	client := &fakeClient{accounts: make(map[flow.Address]*flow.Account)}
+	keys := InMemory{}
+	proposer := flow.HexToAddress("01")
+	payer := flow.HexToAddress("02")
+	newAccount(t, client, keys, proposer)
+	newAccount(t, client, keys, payer)
+	acct := client.accounts[proposer]
+	acct.Keys[0].Weight = 0
+	acct.Keys = append(acct.Keys, &flow.AccountKey{
+		Index: 1, PublicKey: acct.Keys[0].PublicKey, SigAlgo: crypto.ECDSA_P256, HashAlgo: crypto.SHA3_256,
+		Weight: 0, SequenceNumber: 20,
+	})
+	return client, keys, proposer, payer
+}
+
+func TestProposerPool(t *testing.T) {
+	client, keys, proposer, payer := newPoolAccount(t)
+	ctx := context.Background()
+	pool, err := NewProposerPool(ctx, client, proposer)
+	if err != nil {
+		t.Fatalf("NewProposerPool: %v", err)
+	}
+	signer := New(client, keys)
+	roles := Roles{Payer: payer, Authorizers: []flow.Address{payer}}
+
+	// concurrent transactions never share a key, and each key proposes
+	// consecutive sequence numbers
+	var (
+		mu    sync.Mutex
+		inUse = map[int]bool{}
+		next  = map[int]uint64{0: 7, 1: 20}
+		wg    sync.WaitGroup
+	)
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			lease, err := pool.Acquire(ctx)
+			if err != nil {
+				t.Errorf("Acquire: %v", err)
+				return
+			}
+			mu.Lock()
+			if inUse[lease.KeyIndex] || lease.SequenceNumber != next[lease.KeyIndex] {
+				t.Errorf("Key %d leased at sequence %d, in use %v, expected %d", lease.KeyIndex, lease.SequenceNumber, inUse[lease.KeyIndex], next[lease.KeyIndex])
+			}
+			inUse[lease.KeyIndex] = true
+			next[lease.KeyIndex]++
+			mu.Unlock()
+
+			tx := flow.NewTransaction()
+			if err := signer.SignWithLease(ctx, roles, lease, tx); err != nil {
+				t.Errorf("SignWithLease: %v", err)
+				lease.Release(err)
+				return
+			}
+			lease.Sent(tx.ID())
+
+			mu.Lock()
+			inUse[lease.KeyIndex] = false
+			mu.Unlock()
+			pool.Complete(tx.ID(), nil)
+		}()
+	}
+	wg.Wait()
+	if next[0]-7+next[1]-20 != 50 || pool.InFlight() != 0 {
+		t.Fatalf("Expected 50 completed leases, got sequences %v and %d in flight", next, pool.InFlight())
+	}
+
+	// both keys in flight, acquiring waits for a transaction to complete
+	first, _ := pool.Acquire(ctx)
+	second, _ := pool.Acquire(ctx)
+	first.Sent(flow.Identifier{1})
+	second.Sent(flow.Identifier{2})
+	waitCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if _, err := pool.Acquire(waitCtx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected DeadlineExceeded, got: %v", err)
+	}
+	pool.Complete(flow.Identifier{1}, nil)
+	lease, err := pool.Acquire(ctx)
+	if err != nil || lease.KeyIndex != first.KeyIndex || lease.SequenceNumber != first.SequenceNumber+1 {
+		t.Fatalf("Expected key %d sequence %d, got: %+v %v", first.KeyIndex, first.SequenceNumber+1, lease, err)
+	}
+
+	// a released lease returns its sequence number
+	lease.Release(nil)
+	again, err := pool.Acquire(ctx)
+	if err != nil || again.KeyIndex != lease.KeyIndex || again.SequenceNumber != lease.SequenceNumber {
+		t.Fatalf("Expected released key %d sequence %d, got: %+v %v", lease.KeyIndex, lease.SequenceNumber, again, err)
+	}
+	again.Release(nil)
+	pool.Complete(flow.Identifier{2}, nil)
+}
+
+func TestProposerPoolResync(t *testing.T) {
+	client, keys, proposer, payer := newPoolAccount(t)
+	ctx := context.Background()
+	pool, err := NewProposerPool(ctx, client, proposer, 0)
+	if err != nil {
+		t.Fatalf("NewProposerPool: %v", err)
+	}
+	signer := New(client, keys)
+
+	// signing with a lease proposes the leased sequence number with the weight
+	// 0 proposal key
+	lease, err := pool.Acquire(ctx)
+	if err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+	tx := flow.NewTransaction()
+	if err := signer.SignWithLease(ctx, Roles{Payer: payer}, lease, tx); err != nil {
+		t.Fatalf("SignWithLease: %v", err)
+	}
+	if tx.ProposalKey.Address != proposer || tx.ProposalKey.SequenceNumber != 7 || len(tx.PayloadSignatures) != 1 {
+		t.Fatalf("Expected proposal sequence 7 signed by the proposal key, got: %+v %v", tx.ProposalKey, tx.PayloadSignatures)
+	}
+	lease.Sent(tx.ID())
+
+	// the key isn't leased again while its transaction is in flight
+	waitCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if _, err := pool.Acquire(waitCtx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected DeadlineExceeded while in flight, got: %v", err)
+	}
+
+	// the transaction expired so the chain is still at 7
+	pool.Complete(tx.ID(), errors.New("expired"))
+	lease, err = pool.Acquire(ctx)
+	if err != nil || lease.SequenceNumber != 7 {
+		t.Fatalf("Expected resynced sequence 7, got: %+v %v", lease, err)
+	}
+	if err := signer.SignWithLease(ctx, Roles{Proposer: payer, Payer: payer}, lease, flow.NewTransaction()); !errors.Is(err, ErrLeaseProposer) {
+		t.Fatalf("Expected ErrLeaseProposer, got: %v", err)
+	}
+	lease.Release(nil)
+
+	client.accounts[proposer].Keys[0].Revoked = true
+	pool.Resync()
+	if _, err := pool.Acquire(ctx); !errors.Is(err, ErrRevokedKey) {
+		t.Fatalf("Expected ErrRevokedKey, got: %v", err)
+	}
+}
@@ -0,0 +1,430 @@
+// Package signing finalizes and signs flow transactions for a set of signer
+// roles using keys from a pluggable KeyProvider.
+package signing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/onflow/flow-go-sdk"
+	"github.com/onflow/flow-go-sdk/crypto"
+	"google.golang.org/grpc"
+)
+
+var (
+	// ErrNoPayer is returned when the roles have no payer
+	ErrNoPayer = errors.New("transaction payer must be specified")
+	// ErrAuthorizers is returned when the roles name different authorizers than
+	// the transaction already declares
+	ErrAuthorizers = errors.New("authorizers do not match transaction")
+	// ErrNoKey is returned when the key provider has no key for an account
+	ErrNoKey = errors.New("no signing key for account")
+	// ErrKeyMismatch is returned when a key doesn't match the account key on chain
+	ErrKeyMismatch = errors.New("key does not match account key")
+	// ErrRevokedKey is returned when a key has been revoked on chain
+	ErrRevokedKey = errors.New("account key is revoked")
+	// ErrInsufficientWeight is matched by a WeightError
+	ErrInsufficientWeight = errors.New("keys do not reach the weight threshold")
+)
+
+// WeightError is returned when the usable keys of an account don't add up to
+// flow.AccountKeyWeightThreshold. It unwraps to the reason the first rejected
+// key couldn't be used, if any.
+type WeightError struct {
+	Address flow.Address
+	// Weight is the summed weight of the usable keys
+	Weight int
+	// Rejected holds the reason each unusable key was rejected
+	Rejected []error
+}
+
+func (e *WeightError) Error() string {
+	msg := fmt.Sprintf("account %s: usable keys have weight %d of %d", e.Address, e.Weight, flow.AccountKeyWeightThreshold)
+	for _, err := range e.Rejected {
+		msg += "; " + err.Error()
+	}
+	return msg
+}
+
+// Is reports whether target is ErrInsufficientWeight
+func (e *WeightError) Is(target error) bool {
+	return target == ErrInsufficientWeight
+}
+
+func (e *WeightError) Unwrap() error {
+	if len(e.Rejected) == 0 {
+		return nil
+	}
+	return e.Rejected[0]
+}
+
+// Roles assigns the accounts that propose, pay for and authorize a transaction.
+// An account can hold several roles, it signs once either way.
+type Roles struct {
+	Proposer    flow.Address
+	Payer       flow.Address
+	Authorizers []flow.Address
+}
+
+// Key is a key able to sign for an account. It is checked against the account
+// key at Index on chain before it is used.
+type Key struct {
+	// Index is the index of the key on the account
+	Index    int
+	SigAlgo  crypto.SignatureAlgorithm
+	HashAlgo crypto.HashAlgorithm
+	// PublicKey is compared with the account key when set
+	PublicKey crypto.PublicKey
+	Signer    crypto.Signer
+}
+
+// check returns an error unless the account key at the index of k is an active
+// key with the same algorithms, and public key when known
+func (k Key) check(account *flow.Account) error {
+	accountKey, err := accountKey(account, k.Index)
+	if err != nil {
+		return err
+	}
+	if accountKey.Revoked {
+		return fmt.Errorf("%w: %s key %d", ErrRevokedKey, account.Address, k.Index)
+	}
+	if accountKey.SigAlgo != k.SigAlgo || accountKey.HashAlgo != k.HashAlgo {
+		return fmt.Errorf("%w: %s key %d is %s/%s, signer is %s/%s", ErrKeyMismatch, account.Address, k.Index,
+			accountKey.SigAlgo, accountKey.HashAlgo, k.SigAlgo, k.HashAlgo)
+	}
+	if k.PublicKey != nil && !k.PublicKey.Equals(accountKey.PublicKey) {
+		return fmt.Errorf("%w: %s key %d has a different public key", ErrKeyMismatch, account.Address, k.Index)
+	}
+	return nil
+}
+
+// KeyConfig is a private key held in memory that signs as the account key at Index
+type KeyConfig struct {
+	Index      int
+	PrivateKey crypto.PrivateKey
+	HashAlgo   crypto.HashAlgorithm
+}
+
+// Key returns the signing key of the configuration
+func (c KeyConfig) Key() Key {
+	return Key{
+		Index:     c.Index,
+		SigAlgo:   c.PrivateKey.Algorithm(),
+		HashAlgo:  c.HashAlgo,
+		PublicKey: c.PrivateKey.PublicKey(),
+		Signer:    crypto.NewInMemorySigner(c.PrivateKey, c.HashAlgo),
+	}
+}
+
+// Keyring is a KeyProvider of private keys held in memory, configured per account
+type Keyring map[flow.Address][]KeyConfig
+
+// Keys returns the configured keys of the account in order
+func (k Keyring) Keys(_ context.Context, address flow.Address) ([]Key, error) {
+	configs, ok := k[address]
+	if !ok || len(configs) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNoKey, address)
+	}
+	keys := make([]Key, len(configs))
+	for i, c := range configs {
+		keys[i] = c.Key()
+	}
+	return keys, nil
+}
+
+// KeyProvider returns the keys available to sign for an account. Keys are used
+// in order until their weight on chain reaches flow.AccountKeyWeightThreshold.
+type KeyProvider interface {
+	Keys(ctx context.Context, address flow.Address) ([]Key, error)
+}
+
+// InMemory is a KeyProvider of one private key per account, held in memory.
+// Keys sign as the first key of the account using SHA3_256, use a Keyring for
+// other key indices or hash algorithms.
+type InMemory map[flow.Address]crypto.PrivateKey
+
+// Keys returns the key of the account
+func (m InMemory) Keys(_ context.Context, address flow.Address) ([]Key, error) {
+	key, ok := m[address]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNoKey, address)
+	}
+	return []Key{KeyConfig{Index: 0, PrivateKey: key, HashAlgo: crypto.SHA3_256}.Key()}, nil
+}
+
+// Client is the subset of the flow access api used to finalize transactions
+type Client interface {
+	GetLatestBlock(ctx context.Context, isSealed bool, opts ...grpc.CallOption) (*flow.Block, error)
+	GetAccount(ctx context.Context, address flow.Address, opts ...grpc.CallOption) (*flow.Account, error)
+}
+
+// Signer finalizes and signs transactions
+type Signer struct {
+	client Client
+	keys   KeyProvider
+}
+
+// New returns a signer reading accounts and blocks from client and signing with
+// keys from the provider
+func New(client Client, keys KeyProvider) *Signer {
+	return &Signer{client: client, keys: keys}
+}
+
+// Sign sets the payer, proposal key, reference block and authorizers of tx and
+// signs it. Authorizers already declared by the transaction must match the roles,
+// which may then leave them out. The proposer and authorizers sign the payload
+// unless they are the payer, each account once, and the payer signs the envelope.
+// A proposer that is neither payer nor authorizer signs with its proposal key
+// alone, whatever its weight. Transactions with an envelope signature are left
+// untouched.
+func (s *Signer) Sign(ctx context.Context, roles Roles, tx *flow.Transaction) error {
+	return s.sign(ctx, roles, nil, tx)
+}
+
+// SignWithLease signs like Sign, proposing with the key and sequence number of a
+// lease from a ProposerPool instead of reading them from chain. The proposer of
+// roles must be the pool account or left empty.
+func (s *Signer) SignWithLease(ctx context.Context, roles Roles, lease *Lease, tx *flow.Transaction) error {
+	if roles.Proposer != flow.EmptyAddress && roles.Proposer != lease.Address {
+		return fmt.Errorf("%w: proposer %s, lease for %s", ErrLeaseProposer, roles.Proposer, lease.Address)
+	}
+	roles.Proposer = lease.Address
+	return s.sign(ctx, roles, lease, tx)
+}
+
+func (s *Signer) sign(ctx context.Context, roles Roles, lease *Lease, tx *flow.Transaction) error {
+	// Nothing to do if envelope already signed
+	if len(tx.EnvelopeSignatures) > 0 {
+		return nil
+	}
+
+	proposer, err := setRoles(roles, tx)
+	if err != nil {
+		return err
+	}
+
+	// the proposal key is the leased key, or else the first key provided for
+	// the proposer using its current sequence number on chain
+	proposalIndex := -1
+	if lease != nil {
+		proposalIndex = lease.KeyIndex
+	}
+	proposerKeys, proposerAcct, err := s.accountKeys(ctx, proposer, proposalIndex, false)
+	if err != nil {
+		return err
+	}
+	proposalKey, err := proposalKeyOf(proposerAcct, proposerKeys[0].Index)
+	if err != nil {
+		return err
+	}
+	if lease != nil {
+		proposalKey.SequenceNumber = lease.SequenceNumber
+	}
+	if err := finalize(ctx, s.client, roles.Payer, proposalKey, tx); err != nil {
+		return err
+	}
+
+	// the proposal key must sign whichever role the proposer signs for
+	required := func(address flow.Address) int {
+		if address == proposer {
+			return proposalKey.KeyIndex
+		}
+		return -1
+	}
+
+	for _, address := range PayloadSigners(tx) {
+		// a proposer that only proposes needs no weight, just the proposal key
+		weighted := containsAddress(tx.Authorizers, address)
+		keys, _, err := s.accountKeys(ctx, address, required(address), weighted)
+		if err != nil {
+			return err
+		}
+		for _, key := range keys {
+			if err := tx.SignPayload(address, key.Index, key.Signer); err != nil {
+				return fmt.Errorf("signing payload as %s: %w", address, err)
+			}
+		}
+	}
+
+	keys, _, err := s.accountKeys(ctx, roles.Payer, required(roles.Payer), true)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if err := tx.SignEnvelope(roles.Payer, key.Index, key.Signer); err != nil {
+			return fmt.Errorf("signing envelope as %s: %w", roles.Payer, err)
+		}
+	}
+
+	return nil
+}
+
+// Prepare sets the payer, proposal key, reference block and authorizers of tx
+// like Sign does, but leaves signing to the holders of the keys, e.g. through an
+// Envelope. The proposal key is the account key at proposalKeyIndex.
+func Prepare(ctx context.Context, client Client, roles Roles, proposalKeyIndex int, tx *flow.Transaction) error {
+	proposer, err := setRoles(roles, tx)
+	if err != nil {
+		return err
+	}
+	proposerAcct, err := client.GetAccount(ctx, proposer)
+	if err != nil {
+		return fmt.Errorf("getting account %s: %w", proposer, err)
+	}
+	proposalKey, err := proposalKeyOf(proposerAcct, proposalKeyIndex)
+	if err != nil {
+		return err
+	}
+	return finalize(ctx, client, roles.Payer, proposalKey, tx)
+}
+
+// PayloadSigners returns the accounts that sign the payload of tx, the proposer
+// and authorizers other than the payer, each once
+func PayloadSigners(tx *flow.Transaction) []flow.Address {
+	var signers []flow.Address
+	seen := map[flow.Address]bool{tx.Payer: true}
+	for _, address := range append([]flow.Address{tx.ProposalKey.Address}, tx.Authorizers...) {
+		if !seen[address] {
+			seen[address] = true
+			signers = append(signers, address)
+		}
+	}
+	return signers
+}
+
+// setRoles sets the authorizers of tx from roles and returns the proposer
+func setRoles(roles Roles, tx *flow.Transaction) (flow.Address, error) {
+	if roles.Payer == flow.EmptyAddress {
+		return flow.EmptyAddress, ErrNoPayer
+	}
+
+	// builders that know their authorizers set them up front, otherwise
+	// take them from the signer roles
+	if len(tx.Authorizers) == 0 {
+		for _, authorizer := range roles.Authorizers {
+			tx.AddAuthorizer(authorizer)
+		}
+	} else if len(roles.Authorizers) > 0 && !sameAddresses(tx.Authorizers, roles.Authorizers) {
+		return flow.EmptyAddress, fmt.Errorf("%w: transaction has %v, roles have %v", ErrAuthorizers, tx.Authorizers, roles.Authorizers)
+	}
+
+	if roles.Proposer == flow.EmptyAddress {
+		return roles.Payer, nil
+	}
+	return roles.Proposer, nil
+}
+
+// proposalKeyOf returns the proposal key for the account key at index with its
+// current sequence number
+func proposalKeyOf(account *flow.Account, index int) (flow.ProposalKey, error) {
+	key, err := accountKey(account, index)
+	if err != nil {
+		return flow.ProposalKey{}, err
+	}
+	if key.Revoked {
+		return flow.ProposalKey{}, fmt.Errorf("%w: %s key %d", ErrRevokedKey, account.Address, index)
+	}
+	return flow.ProposalKey{Address: account.Address, KeyIndex: key.Index, SequenceNumber: key.SequenceNumber}, nil
+}
+
+// finalize sets the payer, proposal key and latest sealed reference block of tx
+func finalize(ctx context.Context, client Client, payer flow.Address, proposalKey flow.ProposalKey, tx *flow.Transaction) error {
+	block, err := client.GetLatestBlock(ctx, true)
+	if err != nil {
+		return fmt.Errorf("getting reference block: %w", err)
+	}
+
+	tx.SetPayer(payer)
+	tx.SetProposalKey(proposalKey.Address, proposalKey.KeyIndex, proposalKey.SequenceNumber)
+	tx.SetReferenceBlockID(block.ID)
+	return nil
+}
+
+// accountKeys returns the provided keys of an account that sign for it, after
+// checking them against the account on chain. Keys are taken in order, skipping
+// unusable ones, until their summed weight reaches the threshold, or only the
+// first usable key unless weighted. The key at index first, unless negative, is
+// taken before the others and must be usable.
+func (s *Signer) accountKeys(ctx context.Context, address flow.Address, first int, weighted bool) ([]Key, *flow.Account, error) {
+	keys, err := s.keys.Keys(ctx, address)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(keys) == 0 {
+		return nil, nil, fmt.Errorf("%w: %s", ErrNoKey, address)
+	}
+	if first >= 0 {
+		ordered := make([]Key, 0, len(keys))
+		for _, key := range keys {
+			if key.Index == first {
+				ordered = append(ordered, key)
+			}
+		}
+		if len(ordered) == 0 {
+			return nil, nil, fmt.Errorf("%w: %s key %d", ErrNoKey, address, first)
+		}
+		for _, key := range keys {
+			if key.Index != first {
+				ordered = append(ordered, key)
+			}
+		}
+		keys = ordered
+	}
+
+	account, err := s.client.GetAccount(ctx, address)
+	if err != nil {
+		return nil, nil, fmt.Errorf("getting account %s: %w", address, err)
+	}
+
+	var selected []Key
+	weightErr := &WeightError{Address: address}
+	used := make(map[int]bool)
+	for _, key := range keys {
+		if used[key.Index] {
+			continue
+		}
+		if err := key.check(account); err != nil {
+			if key.Index == first {
+				return nil, nil, err
+			}
+			weightErr.Rejected = append(weightErr.Rejected, err)
+			continue
+		}
+		if !weighted {
+			return []Key{key}, account, nil
+		}
+		used[key.Index] = true
+		selected = append(selected, key)
+
+		accountKey, _ := accountKey(account, key.Index)
+		weightErr.Weight += accountKey.Weight
+		if weightErr.Weight >= flow.AccountKeyWeightThreshold {
+			return selected, account, nil
+		}
+	}
+	return nil, nil, weightErr
+}
+
+// accountKey returns the key of the account at index
+func accountKey(account *flow.Account, index int) (*flow.AccountKey, error) {
+	for _, key := range account.Keys {
+		if key.Index == index {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("account %s has no key %d", account.Address, index)
+}
+
+// sameAddresses reports whether a and b hold the same addresses in the same order
+func sameAddresses(a, b []flow.Address) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
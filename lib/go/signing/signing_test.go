@@ -0,0 +1,233 @@
+package signing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/onflow/flow-go-sdk"
+	"github.com/onflow/flow-go-sdk/crypto"
+	"google.golang.org/grpc"
+)
+
+// fakeClient serves accounts from memory and a fixed latest block
+type fakeClient struct {
+	accounts map[flow.Address]*flow.Account
+}
+
+func (c *fakeClient) GetLatestBlock(context.Context, bool, ...grpc.CallOption) (*flow.Block, error) {
+	return &flow.Block{BlockHeader: flow.BlockHeader{ID: flow.HexToID("01")}}, nil
+}
+
+func (c *fakeClient) GetAccount(_ context.Context, address flow.Address, _ ...grpc.CallOption) (*flow.Account, error) {
+	acct, ok := c.accounts[address]
+	if !ok {
+		return nil, errors.New("account not found")
+	}
+	return acct, nil
+}
+
+// newAccount adds an account with a single full weight key to the client and provider
+func newAccount(t *testing.T, client *fakeClient, keys InMemory, address flow.Address) {
+	t.Helper()
+
+	seed := make([]byte, crypto.MinSeedLength)
+	copy(seed, address.Bytes())
+	priv, err := crypto.GeneratePrivateKey(crypto.ECDSA_P256, seed)
+	if err != nil {
+		t.Fatalf("GeneratePrivateKey: %v", err)
+	}
+	keys[address] = priv
+	client.accounts[address] = &flow.Account{
+		Address: address,
+		Keys: []*flow.AccountKey{{
+			Index:          0,
+			PublicKey:      priv.PublicKey(),
+			SigAlgo:        crypto.ECDSA_P256,
+			HashAlgo:       crypto.SHA3_256,
+			Weight:         flow.AccountKeyWeightThreshold,
+			SequenceNumber: 7,
+		}},
+	}
+}
+
+func TestSign(t *testing.T) {
+	client := &fakeClient{accounts: make(map[flow.Address]*flow.Account)}
+	keys := make(InMemory)
+	payer := flow.HexToAddress("01")
+	user := flow.HexToAddress("02")
+	other := flow.HexToAddress("03")
+	for _, addr := range []flow.Address{payer, user, other} {
+		newAccount(t, client, keys, addr)
+	}
+	signer := New(client, keys)
+
+	// the proposer is also an authorizer and signs the payload once
+	tx := flow.NewTransaction()
+	if err := signer.Sign(context.Background(), Roles{Proposer: user, Payer: payer, Authorizers: []flow.Address{user, other}}, tx); err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+	if tx.ProposalKey.Address != user || tx.ProposalKey.SequenceNumber != 7 || tx.Payer != payer {
+		t.Fatalf("Unexpected proposal key %+v or payer %s", tx.ProposalKey, tx.Payer)
+	}
+	if len(tx.PayloadSignatures) != 2 || len(tx.EnvelopeSignatures) != 1 {
+		t.Fatalf("Expected 2 payload and 1 envelope signatures, got: %d and %d", len(tx.PayloadSignatures), len(tx.EnvelopeSignatures))
+	}
+
+	// the payer signs only the envelope
+	tx = flow.NewTransaction()
+	if err := signer.Sign(context.Background(), Roles{Proposer: payer, Payer: payer, Authorizers: []flow.Address{payer}}, tx); err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+	if len(tx.PayloadSignatures) != 0 || len(tx.EnvelopeSignatures) != 1 {
+		t.Fatalf("Expected only an envelope signature, got: %d and %d", len(tx.PayloadSignatures), len(tx.EnvelopeSignatures))
+	}
+
+	// authorizers declared by the transaction must match the roles
+	tx = flow.NewTransaction().AddAuthorizer(other)
+	if err := signer.Sign(context.Background(), Roles{Payer: payer, Authorizers: []flow.Address{user}}, tx); !errors.Is(err, ErrAuthorizers) {
+		t.Fatalf("Expected ErrAuthorizers, got: %v", err)
+	}
+
+	tx = flow.NewTransaction()
+	if err := signer.Sign(context.Background(), Roles{Payer: flow.HexToAddress("04")}, tx); !errors.Is(err, ErrNoKey) {
+		t.Fatalf("Expected ErrNoKey, got: %v", err)
+	}
+	if err := signer.Sign(context.Background(), Roles{}, tx); !errors.Is(err, ErrNoPayer) {
+		t.Fatalf("Expected ErrNoPayer, got: %v", err)
+	}
+}
+
+func TestKeyring(t *testing.T) {
+	client := &fakeClient{accounts: make(map[flow.Address]*flow.Account)}
+	custody := flow.HexToAddress("05")
+
+	seed := make([]byte, crypto.MinSeedLength)
+	priv, err := crypto.GeneratePrivateKey(crypto.ECDSA_secp256k1, seed)
+	if err != nil {
+		t.Fatalf("GeneratePrivateKey: %v", err)
+	}
+	client.accounts[custody] = &flow.Account{
+		Address: custody,
+		Keys: []*flow.AccountKey{
+			{Index: 0, PublicKey: priv.PublicKey(), SigAlgo: crypto.ECDSA_secp256k1, HashAlgo: crypto.SHA2_256, Weight: 1000, Revoked: true},
+			{Index: 1, PublicKey: priv.PublicKey(), SigAlgo: crypto.ECDSA_secp256k1, HashAlgo: crypto.SHA2_256, Weight: 1000, SequenceNumber: 3},
+		},
+	}
+	roles := Roles{Proposer: custody, Payer: custody, Authorizers: []flow.Address{custody}}
+
+	keys := Keyring{custody: {{Index: 1, PrivateKey: priv, HashAlgo: crypto.SHA2_256}}}
+	tx := flow.NewTransaction()
+	if err := New(client, keys).Sign(context.Background(), roles, tx); err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+	if tx.ProposalKey.KeyIndex != 1 || tx.ProposalKey.SequenceNumber != 3 || tx.EnvelopeSignatures[0].KeyIndex != 1 {
+		t.Fatalf("Expected signing with key 1, got proposal key %+v", tx.ProposalKey)
+	}
+
+	for _, c := range []struct {
+		config KeyConfig
+		err    error
+	}{
+		{KeyConfig{Index: 0, PrivateKey: priv, HashAlgo: crypto.SHA2_256}, ErrRevokedKey},
+		{KeyConfig{Index: 1, PrivateKey: priv, HashAlgo: crypto.SHA3_256}, ErrKeyMismatch},
+	} {
+		keys := Keyring{custody: {c.config}}
+		if err := New(client, keys).Sign(context.Background(), roles, flow.NewTransaction()); !errors.Is(err, c.err) {
+			t.Fatalf("Expected %v, got: %v", c.err, err)
+		}
+	}
+
+	// a different private key for the same index
+	other, _ := crypto.GeneratePrivateKey(crypto.ECDSA_secp256k1, append(make([]byte, crypto.MinSeedLength-1), 1))
+	keys = Keyring{custody: {{Index: 1, PrivateKey: other, HashAlgo: crypto.SHA2_256}}}
+	if err := New(client, keys).Sign(context.Background(), roles, flow.NewTransaction()); !errors.Is(err, ErrKeyMismatch) {
+		t.Fatalf("Expected ErrKeyMismatch, got: %v", err)
+	}
+}
+
+func TestWeightedKeys(t *testing.T) {
+	client := &fakeClient{accounts: make(map[flow.Address]*flow.Account)}
+	admin := flow.HexToAddress("06")
+	payer := flow.HexToAddress("01")
+	keys := InMemory{}
+	newAccount(t, client, keys, payer)
+
+	// a 2-of-3 multisig
+	acct := &flow.Account{Address: admin}
+	var configs []KeyConfig
+	for i := 0; i < 3; i++ {
+		seed := make([]byte, crypto.MinSeedLength)
+		seed[0] = byte(i + 1)
+		priv, err := crypto.GeneratePrivateKey(crypto.ECDSA_P256, seed)
+		if err != nil {
+			t.Fatalf("GeneratePrivateKey: %v", err)
+		}
+		acct.Keys = append(acct.Keys, &flow.AccountKey{
+			Index: i, PublicKey: priv.PublicKey(), SigAlgo: crypto.ECDSA_P256, HashAlgo: crypto.SHA3_256, Weight: 500,
+		})
+		configs = append(configs, KeyConfig{Index: i, PrivateKey: priv, HashAlgo: crypto.SHA3_256})
+	}
+	client.accounts[admin] = acct
+
+	provider := func(adminKeys []KeyConfig) KeyProvider {
+		return Keyring{
+			admin: adminKeys,
+			payer: {{Index: 0, PrivateKey: keys[payer], HashAlgo: crypto.SHA3_256}},
+		}
+	}
+	roles := Roles{Proposer: admin, Payer: payer, Authorizers: []flow.Address{admin}}
+
+	// two keys reach the threshold, the third isn't used
+	tx := flow.NewTransaction()
+	if err := New(client, provider(configs)).Sign(context.Background(), roles, tx); err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+	if len(tx.PayloadSignatures) != 2 {
+		t.Fatalf("Expected 2 payload signatures, got: %d", len(tx.PayloadSignatures))
+	}
+
+	err := New(client, provider(configs[:1])).Sign(context.Background(), roles, flow.NewTransaction())
+	var weightErr *WeightError
+	if !errors.As(err, &weightErr) || weightErr.Weight != 500 {
+		t.Fatalf("Expected WeightError with weight 500, got: %v", err)
+	}
+
+	// a revoked key is skipped and reported
+	acct.Keys[1].Revoked = true
+	err = New(client, provider(configs[:2])).Sign(context.Background(), roles, flow.NewTransaction())
+	if !errors.Is(err, ErrInsufficientWeight) || !errors.Is(err, ErrRevokedKey) {
+		t.Fatalf("Expected ErrInsufficientWeight caused by ErrRevokedKey, got: %v", err)
+	}
+	if err := New(client, provider(configs)).Sign(context.Background(), roles, flow.NewTransaction()); err != nil {
+		t.Fatalf("Sign with keys 0 and 2: %v", err)
+	}
+}
+
+func TestProposalKeyWeight(t *testing.T) {
+	client := &fakeClient{accounts: make(map[flow.Address]*flow.Account)}
+	keys := make(InMemory)
+	payer := flow.HexToAddress("01")
+	proposer := flow.HexToAddress("07")
+	for _, addr := range []flow.Address{payer, proposer} {
+		newAccount(t, client, keys, addr)
+	}
+	client.accounts[proposer].Keys[0].Weight = 0
+	signer := New(client, keys)
+
+	// a proposer that only proposes signs with its weight 0 proposal key
+	tx := flow.NewTransaction()
+	if err := signer.Sign(context.Background(), Roles{Proposer: proposer, Payer: payer, Authorizers: []flow.Address{payer}}, tx); err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+	if len(tx.PayloadSignatures) != 1 || tx.PayloadSignatures[0].Address != proposer || tx.PayloadSignatures[0].KeyIndex != 0 {
+		t.Fatalf("Expected a payload signature by the proposal key, got: %v", tx.PayloadSignatures)
+	}
+
+	// authorizing needs the weight threshold
+	err := signer.Sign(context.Background(), Roles{Proposer: proposer, Payer: payer, Authorizers: []flow.Address{proposer}}, flow.NewTransaction())
+	var weightErr *WeightError
+	if !errors.As(err, &weightErr) || weightErr.Address != proposer || weightErr.Weight != 0 {
+		t.Fatalf("Expected WeightError for the proposer, got: %v", err)
+	}
+}
@@ -0,0 +1,198 @@
+// Package submit sends flow transactions and polls their results until they
+// reach a requested status, backing off between polls.
+package submit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/onflow/flow-go-sdk"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Expiry is the number of blocks after its reference block a transaction can
+// be included in
+const Expiry = 600
+
+// ErrExpired is matched by an ExpiredError
+var ErrExpired = errors.New("transaction expired")
+
+// ExpiredError is returned when a transaction can no longer be included because
+// its reference block is too old
+type ExpiredError struct {
+	ID               flow.Identifier
+	ReferenceBlockID flow.Identifier
+	// ReferenceHeight and LatestHeight are zero when the access node rejected
+	// the transaction or reported it expired
+	ReferenceHeight uint64
+	LatestHeight    uint64
+}
+
+func (e *ExpiredError) Error() string {
+	if e.LatestHeight == 0 {
+		return fmt.Sprintf("transaction %s expired: reference block %s", e.ID, e.ReferenceBlockID)
+	}
+	return fmt.Sprintf("transaction %s expired: reference block %s at height %d, sealed height %d",
+		e.ID, e.ReferenceBlockID, e.ReferenceHeight, e.LatestHeight)
+}
+
+// Is reports whether target is ErrExpired
+func (e *ExpiredError) Is(target error) bool {
+	return target == ErrExpired
+}
+
+// Client is the subset of the flow access api used to submit transactions
+type Client interface {
+	SendTransaction(ctx context.Context, tx flow.Transaction, opts ...grpc.CallOption) error
+	GetTransactionResult(ctx context.Context, txID flow.Identifier, opts ...grpc.CallOption) (*flow.TransactionResult, error)
+	GetLatestBlockHeader(ctx context.Context, isSealed bool, opts ...grpc.CallOption) (*flow.BlockHeader, error)
+	GetBlockHeaderByID(ctx context.Context, blockID flow.Identifier, opts ...grpc.CallOption) (*flow.BlockHeader, error)
+}
+
+// Backoff is the delay between polls, growing by Multiplier from Initial up to Max
+type Backoff struct {
+	Initial    time.Duration
+	Max        time.Duration
+	Multiplier float64
+}
+
+// DefaultBackoff polls quickly at first for emulators and slows down to the
+// block rate of live networks
+var DefaultBackoff = Backoff{Initial: 100 * time.Millisecond, Max: 5 * time.Second, Multiplier: 2}
+
+// next returns the delay after d
+func (b Backoff) next(d time.Duration) time.Duration {
+	if d == 0 {
+		return b.Initial
+	}
+	d = time.Duration(float64(d) * b.Multiplier)
+	if d > b.Max {
+		return b.Max
+	}
+	return d
+}
+
+// Submitter sends transactions and waits for them to reach a status
+type Submitter struct {
+	Client Client
+	// Until is the status to wait for, TransactionStatusSealed if unknown
+	Until flow.TransactionStatus
+	// Backoff is the delay between polls, DefaultBackoff if zero
+	Backoff Backoff
+	// OnStatus, if set, is called with the result whenever the status changes
+	OnStatus func(*flow.TransactionResult)
+}
+
+// Submit sends tx and waits for it to reach the status of s. The returned
+// result holds the execution error, if any, once the transaction is executed.
+func (s *Submitter) Submit(ctx context.Context, tx *flow.Transaction) (*flow.TransactionResult, error) {
+	if err := s.Client.SendTransaction(ctx, *tx); err != nil {
+		if isExpired(err) {
+			return nil, &ExpiredError{ID: tx.ID(), ReferenceBlockID: tx.ReferenceBlockID}
+		}
+		return nil, fmt.Errorf("sending transaction %s: %w", tx.ID(), err)
+	}
+	return s.Wait(ctx, tx.ID(), tx.ReferenceBlockID)
+}
+
+// Wait polls the result of a sent transaction until it reaches the status of s.
+// It fails with an ExpiredError once the latest sealed block is past the expiry
+// of the reference block and the transaction is still pending.
+func (s *Submitter) Wait(ctx context.Context, txID, referenceBlockID flow.Identifier) (*flow.TransactionResult, error) {
+	until := s.Until
+	if until == flow.TransactionStatusUnknown || until == flow.TransactionStatusExpired {
+		until = flow.TransactionStatusSealed
+	}
+	backoff := s.Backoff
+	if backoff == (Backoff{}) {
+		backoff = DefaultBackoff
+	}
+
+	var (
+		last      = flow.TransactionStatusUnknown
+		refHeight uint64
+		delay     time.Duration
+	)
+	for {
+		result, err := s.Client.GetTransactionResult(ctx, txID)
+		if err != nil && status.Code(err) != codes.NotFound {
+			return nil, fmt.Errorf("getting result of %s: %w", txID, err)
+		}
+		if err != nil {
+			// not yet known to the access node
+			result = &flow.TransactionResult{Status: flow.TransactionStatusUnknown}
+		}
+
+		if result.Status != last {
+			last = result.Status
+			if s.OnStatus != nil {
+				s.OnStatus(result)
+			}
+		}
+
+		switch {
+		case result.Status == flow.TransactionStatusExpired:
+			return nil, &ExpiredError{ID: txID, ReferenceBlockID: referenceBlockID}
+		case result.Status >= until:
+			return result, nil
+		case result.Status <= flow.TransactionStatusPending:
+			expired, err := s.expired(ctx, txID, referenceBlockID, &refHeight)
+			if err != nil {
+				return nil, err
+			}
+			if expired != nil {
+				return nil, expired
+			}
+		}
+
+		delay = backoff.next(delay)
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// expired returns an ExpiredError if the latest sealed block is past the expiry
+// of the reference block. The reference height is looked up once into refHeight.
+func (s *Submitter) expired(ctx context.Context, txID, referenceBlockID flow.Identifier, refHeight *uint64) (*ExpiredError, error) {
+	if *refHeight == 0 {
+		ref, err := s.Client.GetBlockHeaderByID(ctx, referenceBlockID)
+		if err != nil {
+			return nil, fmt.Errorf("getting reference block %s: %w", referenceBlockID, err)
+		}
+		*refHeight = ref.Height
+	}
+	latest, err := s.Client.GetLatestBlockHeader(ctx, true)
+	if err != nil {
+		return nil, fmt.Errorf("getting latest block: %w", err)
+	}
+	if latest.Height <= *refHeight+Expiry {
+		return nil, nil
+	}
+	return &ExpiredError{ID: txID, ReferenceBlockID: referenceBlockID, ReferenceHeight: *refHeight, LatestHeight: latest.Height}, nil
+}
+
+// isExpired reports whether the access node rejected a transaction for its
+// expired reference block
+func isExpired(err error) bool {
+	return status.Code(err) == codes.InvalidArgument && strings.Contains(err.Error(), "expired")
+}
+
+// Statuses returns a callback for OnStatus sending every status change to a
+// channel of the given capacity. Changes are dropped while the channel is full.
+func Statuses(capacity int) (func(*flow.TransactionResult), <-chan flow.TransactionStatus) {
+	ch := make(chan flow.TransactionStatus, capacity)
+	return func(result *flow.TransactionResult) {
+		select {
+		case ch <- result.Status:
+		default:
+		}
+	}, ch
+}
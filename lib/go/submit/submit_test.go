@@ -0,0 +1,112 @@
+package submit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/onflow/flow-go-sdk"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeClient reports the statuses of a transaction in order, repeating the last
+type fakeClient struct {
+	sendErr  error
+	statuses []flow.TransactionStatus
+	height   uint64
+	polls    int
+}
+
+func (c *fakeClient) SendTransaction(context.Context, flow.Transaction, ...grpc.CallOption) error {
+	return c.sendErr
+}
+
+func (c *fakeClient) GetTransactionResult(context.Context, flow.Identifier, ...grpc.CallOption) (*flow.TransactionResult, error) {
+	i := c.polls
+	if i >= len(c.statuses) {
+		i = len(c.statuses) - 1
+	}
+	c.polls++
+	if c.statuses[i] == flow.TransactionStatusUnknown {
+		return nil, status.Error(codes.NotFound, "transaction not found")
+	}
+	return &flow.TransactionResult{Status: c.statuses[i]}, nil
+}
+
+func (c *fakeClient) GetLatestBlockHeader(context.Context, bool, ...grpc.CallOption) (*flow.BlockHeader, error) {
+	return &flow.BlockHeader{Height: c.height}, nil
+}
+
+func (c *fakeClient) GetBlockHeaderByID(context.Context, flow.Identifier, ...grpc.CallOption) (*flow.BlockHeader, error) {
+	return &flow.BlockHeader{Height: 100}, nil
+}
+
+var fast = Backoff{Initial: time.Millisecond, Max: 2 * time.Millisecond, Multiplier: 2}
+
+func TestSubmit(t *testing.T) {
+	ctx := context.Background()
+	tx := flow.NewTransaction()
+
+	client := &fakeClient{
+		statuses: []flow.TransactionStatus{
+			flow.TransactionStatusUnknown,
+			flow.TransactionStatusPending,
+			flow.TransactionStatusPending,
+			flow.TransactionStatusFinalized,
+			flow.TransactionStatusExecuted,
+			flow.TransactionStatusSealed,
+		},
+		height: 120,
+	}
+	onStatus, statuses := Statuses(10)
+	s := &Submitter{Client: client, Backoff: fast, OnStatus: onStatus}
+	result, err := s.Submit(ctx, tx)
+	if err != nil || result.Status != flow.TransactionStatusSealed {
+		t.Fatalf("Expected sealed result, got: %v %v", result, err)
+	}
+	// Unknown is the initial status and isn't reported
+	if len(statuses) != 4 {
+		t.Fatalf("Expected 4 status changes, got: %d", len(statuses))
+	}
+
+	// stop once finalized
+	client.polls = 0
+	s = &Submitter{Client: client, Backoff: fast, Until: flow.TransactionStatusFinalized}
+	if result, err := s.Submit(ctx, tx); err != nil || result.Status != flow.TransactionStatusFinalized {
+		t.Fatalf("Expected finalized result, got: %v %v", result, err)
+	}
+}
+
+func TestSubmitExpired(t *testing.T) {
+	ctx := context.Background()
+	tx := flow.NewTransaction()
+
+	// pending past the expiry of the reference block
+	client := &fakeClient{statuses: []flow.TransactionStatus{flow.TransactionStatusPending}, height: 100 + Expiry + 1}
+	s := &Submitter{Client: client, Backoff: fast}
+	var expired *ExpiredError
+	if _, err := s.Submit(ctx, tx); !errors.As(err, &expired) || expired.LatestHeight != 100+Expiry+1 {
+		t.Fatalf("Expected ExpiredError, got: %v", err)
+	}
+
+	client = &fakeClient{statuses: []flow.TransactionStatus{flow.TransactionStatusExpired}}
+	if _, err := (&Submitter{Client: client, Backoff: fast}).Submit(ctx, tx); !errors.Is(err, ErrExpired) {
+		t.Fatalf("Expected ErrExpired, got: %v", err)
+	}
+
+	client = &fakeClient{sendErr: status.Error(codes.InvalidArgument, "transaction is expired")}
+	if _, err := (&Submitter{Client: client, Backoff: fast}).Submit(ctx, tx); !errors.Is(err, ErrExpired) {
+		t.Fatalf("Expected ErrExpired from send, got: %v", err)
+	}
+
+	// still pending when the context is done
+	client = &fakeClient{statuses: []flow.TransactionStatus{flow.TransactionStatusPending}, height: 101}
+	ctx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if _, err := (&Submitter{Client: client, Backoff: fast}).Submit(ctx, tx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected DeadlineExceeded, got: %v", err)
+	}
+}
@@ -2,11 +2,14 @@ package tests
 
 import (
 	"context"
+	"errors"
 	"flag"
+	"fmt"
 	"strings"
 	"testing"
 
 	"github.com/arena/arena-cadence/lib/go/arenatoken"
+	"github.com/arena/arena-cadence/lib/go/events"
 	"github.com/arena/arena-cadence/tests/emulator"
 	"github.com/onflow/cadence"
 	"github.com/onflow/flow-go-sdk"
@@ -85,7 +88,7 @@ func TestMintArena(t *testing.T) {
 		}
 
 		// check expected events
-		validateEvents(t, result, []string{
+		validateEvents(t, txRenderer, result, []string{
 			"MinterCreated",
 			"TokensMinted",
 			"TokensDeposited",
@@ -130,7 +133,7 @@ func TestMintArena(t *testing.T) {
 		}
 
 		// check expected events
-		validateEvents(t, result, []string{
+		validateEvents(t, txRenderer, result, []string{
 			"MinterCreated",
 			"TokensMinted",
 			"TokensDeposited",
@@ -197,7 +200,7 @@ func TestBurn(t *testing.T) {
 		}
 
 		// check expected events
-		validateEvents(t, result, []string{
+		validateEvents(t, txRenderer, result, []string{
 			"TokensWithdrawn",
 			"BurnerCreated",
 			"TokensBurned",
@@ -287,6 +290,205 @@ func TestBalance(t *testing.T) {
 		}
 	})
 
+	t.Run("VaultStatus", func(t *testing.T) {
+
+		status, err := txRenderer.VaultStatus(context.Background(), em.Client, em.ServiceAccount)
+		if err != nil {
+			t.Fatalf("Reading vault status: %v", err)
+		}
+		if !status.Ready() || status.Balance == nil || status.Balance.String() != "100000000100.00000000" {
+			t.Fatalf("Expected ready vault with balance %v, got: %+v", "100000000100.00000000", status)
+		}
+
+		// an account that never ran setup_account is reported, not failed
+		newAcct := AddAccount(t, em)
+		status, err = txRenderer.VaultStatus(context.Background(), em.Client, newAcct)
+		if err != nil {
+			t.Fatalf("Reading vault status: %v", err)
+		}
+		if status.HasBalanceLink() || status.Receiver || status.Balance != nil {
+			t.Fatalf("Expected no vault or capabilities, got: %+v", status)
+		}
+	})
+
+	t.Run("Repair", func(t *testing.T) {
+
+		// set up a new account, then drop both of its links
+		newAcct := AddAccount(t, em)
+		tx := txRenderer.SetupAccount()
+		em.SignTx(emulator.TxSigners{Proposer: newAcct, Payer: em.ServiceAccount, Authorizers: []flow.Address{newAcct}}, tx)
+		if result := em.ExecuteTxWaitForSeal(tx); result.Error != nil {
+			t.Fatalf("setup_account tx execution: %v", result.Error)
+		}
+		unlink := flow.NewTransaction().
+			SetScript([]byte(fmt.Sprintf(`
+				import ArenaToken from 0x%s
+				transaction {
+					prepare(signer: AuthAccount) {
+						signer.unlink(ArenaToken.ReceiverPublicPath)
+						signer.unlink(ArenaToken.BalancePublicPath)
+					}
+				}`, em.Contracts["ArenaToken"].Hex()))).
+			SetGasLimit(100)
+		em.SignTx(emulator.TxSigners{Proposer: newAcct, Payer: em.ServiceAccount, Authorizers: []flow.Address{newAcct}}, unlink)
+		if result := em.ExecuteTxWaitForSeal(unlink); result.Error != nil {
+			t.Fatalf("unlink tx execution: %v", result.Error)
+		}
+
+		report, err := txRenderer.InspectAccount(context.Background(), em.Client, newAcct)
+		if err != nil {
+			t.Fatalf("Inspecting account: %v", err)
+		}
+		// no link targets the stored vault, so it isn't seen
+		if report.Receiver.Linked || report.Balance.Linked || report.Vault != nil || !report.Repairable() || len(report.Problems) != 2 {
+			t.Fatalf("Expected a repairable account missing both links, got: %+v", report)
+		}
+
+		tx = txRenderer.RepairAccount()
+		em.SignTx(emulator.TxSigners{Proposer: newAcct, Payer: em.ServiceAccount, Authorizers: []flow.Address{newAcct}}, tx)
+		if result := em.ExecuteTxWaitForSeal(tx); result.Error != nil {
+			t.Fatalf("repair_account tx execution: %v", result.Error)
+		}
+
+		report, err = txRenderer.InspectAccount(context.Background(), em.Client, newAcct)
+		if err != nil {
+			t.Fatalf("Inspecting account: %v", err)
+		}
+		if !report.Ready() || report.Vault == nil || !*report.Vault {
+			t.Fatalf("Expected a ready account, got: %+v", report)
+		}
+
+		// an account without a vault is set up with an empty one
+		newAcct = AddAccount(t, em)
+		report, err = txRenderer.InspectAccount(context.Background(), em.Client, newAcct)
+		if err != nil {
+			t.Fatalf("Inspecting account: %v", err)
+		}
+		if !report.Repairable() {
+			t.Fatalf("Expected an account without a vault to be repairable, got: %+v", report)
+		}
+		tx = txRenderer.RepairAccount()
+		em.SignTx(emulator.TxSigners{Proposer: newAcct, Payer: em.ServiceAccount, Authorizers: []flow.Address{newAcct}}, tx)
+		if result := em.ExecuteTxWaitForSeal(tx); result.Error != nil {
+			t.Fatalf("repair_account tx execution: %v", result.Error)
+		}
+		status, err := txRenderer.VaultStatus(context.Background(), em.Client, newAcct)
+		if err != nil {
+			t.Fatalf("Reading vault status: %v", err)
+		}
+		if !status.Ready() || status.Balance == nil || *status.Balance != 0 {
+			t.Fatalf("Expected a ready empty vault, got: %+v", status)
+		}
+
+		// another resource at the vault path is reported and left alone
+		newAcct = AddAccount(t, em)
+		occupy := flow.NewTransaction().
+			SetScript([]byte(fmt.Sprintf(`
+				import ArenaToken from 0x%s
+				transaction {
+					prepare(signer: AuthAccount) {
+						signer.save(<-ArenaToken.createAdministratorClaimer(), to: ArenaToken.VaultStoragePath)
+						signer.link<&ArenaToken.AdministratorClaimer>(ArenaToken.ReceiverPublicPath, target: ArenaToken.VaultStoragePath)
+					}
+				}`, em.Contracts["ArenaToken"].Hex()))).
+			SetGasLimit(100)
+		em.SignTx(emulator.TxSigners{Proposer: newAcct, Payer: em.ServiceAccount, Authorizers: []flow.Address{newAcct}}, occupy)
+		if result := em.ExecuteTxWaitForSeal(occupy); result.Error != nil {
+			t.Fatalf("occupy tx execution: %v", result.Error)
+		}
+		report, err = txRenderer.InspectAccount(context.Background(), em.Client, newAcct)
+		if err != nil {
+			t.Fatalf("Inspecting account: %v", err)
+		}
+		occupied := fmt.Sprintf("A.%s.ArenaToken.AdministratorClaimer", em.Contracts["ArenaToken"].Hex())
+		if report.Occupied != occupied || report.Repairable() {
+			t.Fatalf("Expected an unrepairable account storing %s, got: %+v", occupied, report)
+		}
+		tx = txRenderer.RepairAccount()
+		em.SignTx(emulator.TxSigners{Proposer: newAcct, Payer: em.ServiceAccount, Authorizers: []flow.Address{newAcct}}, tx)
+		if result := em.ExecuteTxWaitForSeal(tx); result.Error == nil {
+			t.Fatalf("Expected repair_account to fail with another resource at VaultStoragePath")
+		}
+	})
+
+	t.Run("Balances", func(t *testing.T) {
+
+		newAcct := AddAccount(t, em)
+		balances, err := txRenderer.Balances(context.Background(), em.Client, []flow.Address{em.ServiceAccount, newAcct}, 0)
+		if err != nil {
+			t.Fatalf("Reading balances: %v", err)
+		}
+		if len(balances) != 1 || balances[em.ServiceAccount].String() != "100000000100.00000000" {
+			t.Fatalf("Expected only the service account balance, got: %v", balances)
+		}
+	})
+
+}
+
+func TestSupply(t *testing.T) {
+
+	em, teardown := emulator.NewUnit(t, "3569", *dockerLogsOnFail)
+	defer teardown()
+
+	// Deploy ArenaToken contract to service account
+	contractSource := arenatoken.Contract(em.Contracts["FungibleToken"])
+	DeployContract(t, em, em.ServiceAccount, "ArenaToken", contractSource)
+	txRenderer := arenatoken.New(em.Contracts["ArenaToken"], em.Contracts["FungibleToken"])
+
+	// set up a new account and mint tokens to it
+	newAcct := AddAccount(t, em)
+	tx := txRenderer.SetupAccount()
+	em.SignTx(emulator.TxSigners{Proposer: newAcct, Payer: em.ServiceAccount, Authorizers: []flow.Address{newAcct}}, tx)
+	if result := em.ExecuteTxWaitForSeal(tx); result.Error != nil {
+		t.Fatalf("setup_account tx execution: %v", result.Error)
+	}
+	before, err := em.Client.GetLatestBlockHeader(context.Background(), true)
+	if err != nil {
+		t.Fatalf("Reading latest block: %v", err)
+	}
+	amt, _ := cadence.NewUFix64("100.0")
+	tx = txRenderer.MintTokens(newAcct, amt)
+	em.SignTx(emulator.TxSigners{Proposer: em.ServiceAccount, Payer: em.ServiceAccount, Authorizers: []flow.Address{em.ServiceAccount}}, tx)
+	if result := em.ExecuteTxWaitForSeal(tx); result.Error != nil {
+		t.Fatalf("mint_arena tx execution: %v", result.Error)
+	}
+
+	// balance and supply before the mint, by height and by block id
+	for _, block := range []arenatoken.BlockRef{arenatoken.AtHeight(before.Height), arenatoken.AtBlockID(before.ID)} {
+		balance, err := txRenderer.AccountBalance(context.Background(), arenatoken.Pin(em.Client, block), newAcct)
+		if err != nil || balance != 0 {
+			t.Fatalf("Expected no balance at %s, got: %v %v", block, balance, err)
+		}
+		total, err := txRenderer.TotalSupply(context.Background(), arenatoken.Pin(em.Client, block))
+		if err != nil || total.String() != initialBalance {
+			t.Fatalf("Expected total supply %v at %s, got: %v %v", initialBalance, block, total, err)
+		}
+	}
+	req, err := txRenderer.BuildTotalSupply()
+	if err != nil {
+		t.Fatalf("BuildTotalSupply: %v", err)
+	}
+	if _, err := arenatoken.ExecuteScript(context.Background(), em.Client, arenatoken.AtHeight(before.Height+1000), req); !errors.Is(err, arenatoken.ErrSporkRange) {
+		t.Fatalf("Expected ErrSporkRange past the latest height, got: %v", err)
+	}
+
+	total, err := txRenderer.TotalSupply(context.Background(), em.Client)
+	if err != nil {
+		t.Fatalf("Reading total supply: %v", err)
+	}
+	if total.String() != "100000000100.00000000" {
+		t.Fatalf("Expected total supply: %v, got: %v", "100000000100.00000000", total)
+	}
+
+	// the treasury is excluded once even if listed twice, accounts without a vault are skipped
+	noVault := AddAccount(t, em)
+	circulating, err := txRenderer.CirculatingSupply(context.Background(), em.Client, []flow.Address{em.ServiceAccount, em.ServiceAccount, noVault})
+	if err != nil {
+		t.Fatalf("Reading circulating supply: %v", err)
+	}
+	if circulating.String() != "100.00000000" {
+		t.Fatalf("Expected circulating supply: %v, got: %v", "100.00000000", circulating)
+	}
 }
 
 func TestTransfer(t *testing.T) {
@@ -336,7 +538,7 @@ func TestTransfer(t *testing.T) {
 		}
 
 		// check expected events
-		validateEvents(t, result, []string{
+		validateEvents(t, txRenderer, result, []string{
 			"TokensWithdrawn",
 			"TokensDeposited",
 		})
@@ -363,7 +565,7 @@ func TestTransfer(t *testing.T) {
 		}
 
 		// check expected events
-		validateEvents(t, result, []string{
+		validateEvents(t, txRenderer, result, []string{
 			"TokensWithdrawn",
 			"TokensDeposited",
 		})
@@ -567,6 +769,89 @@ func TestTransferAdmininstrator(t *testing.T) {
 
 }
 
+func TestOfferClaimAdministrator(t *testing.T) {
+
+	em, teardown := emulator.NewUnit(t, "3569", *dockerLogsOnFail)
+	defer teardown()
+
+	// Deploy ArenaToken contract to service account
+	contractSource := arenatoken.Contract(em.Contracts["FungibleToken"])
+	DeployContract(t, em, em.ServiceAccount, "ArenaToken", contractSource)
+	txRenderer := arenatoken.New(em.Contracts["ArenaToken"], em.Contracts["FungibleToken"])
+
+	newAcct := AddAccount(t, em)
+	otherAcct := AddAccount(t, em)
+	amount, _ := cadence.NewUFix64("1000.0")
+
+	// Offer the Administrator resource to the new account, signed by the current admin only
+	tx := txRenderer.OfferAdministrator(em.ServiceAccount, newAcct)
+	signers := emulator.TxSigners{
+		Proposer: em.ServiceAccount,
+		Payer:    em.ServiceAccount,
+	}
+	em.SignTx(signers, tx)
+	result := em.ExecuteTxWaitForSeal(tx)
+	if result.Error != nil {
+		t.Fatalf("offer_admin tx execution: %v", result.Error)
+	}
+	validateEvents(t, txRenderer, result, []string{
+		"AdministratorOffered",
+	})
+
+	// Old admin should not be able to mint while the offer is pending
+	tx = txRenderer.MintTokens(em.ServiceAccount, amount)
+	signers = emulator.TxSigners{
+		Proposer:    em.ServiceAccount,
+		Payer:       em.ServiceAccount,
+		Authorizers: []flow.Address{em.ServiceAccount},
+	}
+	em.SignTx(signers, tx)
+	result = em.ExecuteTxWaitForSeal(tx)
+	if result.Error == nil {
+		t.Fatalf("Expected old admin mint to revert but did not")
+	}
+
+	// Accounts other than the recipient should not be able to claim
+	tx = txRenderer.ClaimAdministrator(otherAcct)
+	signers = emulator.TxSigners{
+		Proposer: otherAcct,
+		Payer:    em.ServiceAccount,
+	}
+	em.SignTx(signers, tx)
+	result = em.ExecuteTxWaitForSeal(tx)
+	if result.Error == nil {
+		t.Fatalf("Expected claim by other account to revert but did not")
+	}
+
+	// Claim the Administrator resource, signed by the new admin only
+	tx = txRenderer.ClaimAdministrator(newAcct)
+	signers = emulator.TxSigners{
+		Proposer: newAcct,
+		Payer:    em.ServiceAccount,
+	}
+	em.SignTx(signers, tx)
+	result = em.ExecuteTxWaitForSeal(tx)
+	if result.Error != nil {
+		t.Fatalf("claim_admin tx execution: %v", result.Error)
+	}
+	validateEvents(t, txRenderer, result, []string{
+		"AdministratorClaimed",
+	})
+
+	// New account should now be able to mint
+	tx = txRenderer.MintTokens(em.ServiceAccount, amount)
+	signers = emulator.TxSigners{
+		Proposer:    newAcct,
+		Payer:       em.ServiceAccount,
+		Authorizers: []flow.Address{newAcct},
+	}
+	em.SignTx(signers, tx)
+	result = em.ExecuteTxWaitForSeal(tx)
+	if result.Error != nil {
+		t.Fatalf("Expected new admin to mint successfully: %v", result.Error)
+	}
+}
+
 func TestCreateAccount(t *testing.T) {
 	em, teardown := emulator.NewUnit(t, "3569", *dockerLogsOnFail)
 	defer teardown()
@@ -577,14 +862,177 @@ func TestCreateAccount(t *testing.T) {
 	}
 }
 
-func validateEvents(t *testing.T, result *flow.TransactionResult, expected []string) {
+func TestBatchTransfer(t *testing.T) {
+
+	em, teardown := emulator.NewUnit(t, "3569", *dockerLogsOnFail)
+	defer teardown()
+
+	// Deploy ArenaToken contract to service account
+	contractSource := arenatoken.Contract(em.Contracts["FungibleToken"])
+	DeployContract(t, em, em.ServiceAccount, "ArenaToken", contractSource)
+	txRenderer := arenatoken.New(em.Contracts["ArenaToken"], em.Contracts["FungibleToken"])
+
+	// one recipient is set up, the other never ran setup_account
+	player := AddAccount(t, em)
+	tx := txRenderer.SetupAccount()
+	em.SignTx(emulator.TxSigners{Proposer: player, Payer: em.ServiceAccount, Authorizers: []flow.Address{player}}, tx)
+	if result := em.ExecuteTxWaitForSeal(tx); result.Error != nil {
+		t.Fatalf("setup_account tx execution: %v", result.Error)
+	}
+	noVault := AddAccount(t, em)
+
+	ten, _ := cadence.NewUFix64("10.0")
+	five, _ := cadence.NewUFix64("5.0")
+	amounts := map[flow.Address]cadence.UFix64{player: ten, noVault: five}
+	signers := emulator.TxSigners{Proposer: em.ServiceAccount, Payer: em.ServiceAccount, Authorizers: []flow.Address{em.ServiceAccount}}
 
-	if len(result.Events) != len(expected) {
+	t.Run("Abort", func(t *testing.T) {
+		tx := txRenderer.BatchTransfer(amounts, false)[0]
+		em.SignTx(signers, tx)
+		if result := em.ExecuteTxWaitForSeal(tx); result.Error == nil {
+			t.Fatalf("Expected the batch to abort on a recipient without a receiver")
+		}
+		balance, err := txRenderer.AccountBalance(context.Background(), em.Client, player)
+		if err != nil || balance != 0 {
+			t.Fatalf("Expected no balance after the aborted batch, got: %v %v", balance, err)
+		}
+	})
+
+	t.Run("Skip", func(t *testing.T) {
+		tx := txRenderer.BatchTransfer(amounts, true)[0]
+		em.SignTx(signers, tx)
+		result := em.ExecuteTxWaitForSeal(tx)
+		if result.Error != nil {
+			t.Fatalf("batch_transfer tx execution: %v", result.Error)
+		}
+
+		evs, err := events.For(txRenderer).FromResult(result)
+		if err != nil {
+			t.Fatalf("Decoding events: %v", err)
+		}
+		var skipped []*events.BatchRecipientSkipped
+		for _, ev := range evs {
+			if s, ok := ev.(*events.BatchRecipientSkipped); ok {
+				skipped = append(skipped, s)
+			}
+		}
+		if len(skipped) != 1 || skipped[0].To != noVault || skipped[0].Amount != five {
+			t.Fatalf("Expected %s to be skipped, got: %+v", noVault, skipped)
+		}
+
+		// the skipped amount stays with the signer
+		balance, err := txRenderer.AccountBalance(context.Background(), em.Client, player)
+		if err != nil || balance.String() != "10.00000000" {
+			t.Fatalf("Expected player balance 10.00000000, got: %v %v", balance, err)
+		}
+		balance, err = txRenderer.AccountBalance(context.Background(), em.Client, em.ServiceAccount)
+		if err != nil || balance.String() != "99999999990.00000000" {
+			t.Fatalf("Expected service account balance 99999999990.00000000, got: %v %v", balance, err)
+		}
+	})
+
+	t.Run("Arrays", func(t *testing.T) {
+		req, err := txRenderer.BuildBatchTransferArrays([]flow.Address{player, player}, []cadence.UFix64{ten, five}, false)
+		if err != nil {
+			t.Fatalf("BuildBatchTransferArrays: %v", err)
+		}
+		em.SignTx(signers, req.Transaction)
+		if result := em.ExecuteTxWaitForSeal(req.Transaction); result.Error != nil {
+			t.Fatalf("batch_transfer_arrays tx execution: %v", result.Error)
+		}
+		balance, err := txRenderer.AccountBalance(context.Background(), em.Client, player)
+		if err != nil || balance.String() != "25.00000000" {
+			t.Fatalf("Expected player balance 25.00000000, got: %v %v", balance, err)
+		}
+
+		// amounts must pair up with recipients
+		req, err = txRenderer.BuildBatchTransferArrays([]flow.Address{player}, []cadence.UFix64{ten, five}, false)
+		if err != nil {
+			t.Fatalf("BuildBatchTransferArrays: %v", err)
+		}
+		em.SignTx(signers, req.Transaction)
+		if result := em.ExecuteTxWaitForSeal(req.Transaction); result.Error == nil {
+			t.Fatalf("Expected mismatched recipients and amounts to fail")
+		}
+	})
+}
+
+func validateEvents(t *testing.T, r *arenatoken.ArenaToken, result *flow.TransactionResult, expected []string) {
+
+	evs, err := events.For(r).FromResult(result)
+	if err != nil {
+		t.Fatalf("Decoding events: %v", err)
+	}
+	if len(evs) != len(expected) {
 		t.Fatalf("Unexpected number of events")
 	}
-	for i, e := range result.Events {
-		if !strings.Contains(e.Type, expected[i]) {
-			t.Fatalf("Expected event type: %s, got: %s", expected[i], e.Type)
+	for i, e := range evs {
+		if e.Name() != expected[i] {
+			t.Fatalf("Expected event type: %s, got: %s", expected[i], e.Metadata().Type)
 		}
 	}
 }
+
+func TestNamedDeployment(t *testing.T) {
+	em, teardown := emulator.NewUnit(t, "3569", *dockerLogsOnFail)
+	defer teardown()
+
+	// Deploy ArenaToken and a second token with its own name and paths to the service account
+	contractSource := arenatoken.Contract(em.Contracts["FungibleToken"])
+	DeployContract(t, em, em.ServiceAccount, "ArenaToken", contractSource)
+
+	gold := arenatoken.New(em.ServiceAccount, em.Contracts["FungibleToken"],
+		arenatoken.WithContractName("ArenaGold"),
+		arenatoken.WithPaths(arenatoken.Paths{
+			VaultStorage:        "/storage/arenaGoldVault",
+			ReceiverPublic:      "/public/arenaGoldReceiver",
+			BalancePublic:       "/public/arenaGoldBalance",
+			AdminStorage:        "/storage/arenaGoldAdmin",
+			AdminClaimerStorage: "/storage/arenaGoldAdminClaimer",
+			AdminClaimerPrivate: "/private/arenaGoldAdminClaimer",
+			AdminOfferStorage:   "/storage/arenaGoldAdminOffer",
+		}),
+	)
+	goldSource, err := gold.ContractCode()
+	if err != nil {
+		t.Fatalf("Rendering ArenaGold: %v", err)
+	}
+	DeployContract(t, em, em.ServiceAccount, "ArenaGold", goldSource)
+
+	// setup and mint to a new account with the ArenaGold handle
+	newAcct := AddAccount(t, em)
+	tx := gold.SetupAccount()
+	em.SignTx(emulator.TxSigners{
+		Proposer:    newAcct,
+		Payer:       em.ServiceAccount,
+		Authorizers: []flow.Address{newAcct},
+	}, tx)
+	if result := em.ExecuteTxWaitForSeal(tx); result.Error != nil {
+		t.Fatalf("setup_account tx execution: %v", result.Error)
+	}
+
+	amt, _ := cadence.NewUFix64("100.0")
+	tx = gold.MintTokens(newAcct, amt)
+	em.SignTx(emulator.TxSigners{
+		Proposer:    em.ServiceAccount,
+		Payer:       em.ServiceAccount,
+		Authorizers: []flow.Address{em.ServiceAccount},
+	}, tx)
+	if result := em.ExecuteTxWaitForSeal(tx); result.Error != nil {
+		t.Fatalf("mint_arena tx execution: %v", result.Error)
+	}
+
+	script, args := gold.Balance(newAcct)
+	val, err := em.Client.ExecuteScriptAtLatestBlock(context.Background(), script, args)
+	if err != nil {
+		t.Fatalf("Reading ArenaGold balance: %v", err)
+	}
+	if val.String() != "100.00000000" {
+		t.Fatalf("Incorrect ArenaGold balance, expected: 100.00000000, got: %s", val.String())
+	}
+
+	// the ArenaToken vault of the deployer is untouched
+	if bal := arenaBalance(t, em, em.ServiceAccount); bal.String() != initialBalance {
+		t.Fatalf("Incorrect ArenaToken balance, expected: %s, got: %s", initialBalance, bal.String())
+	}
+}
@@ -10,6 +10,9 @@ import (
 
 	jsoncdc "github.com/onflow/cadence/encoding/json"
 
+	"github.com/arena/arena-cadence/lib/go/flowconfig"
+	"github.com/arena/arena-cadence/lib/go/signing"
+	"github.com/arena/arena-cadence/lib/go/submit"
 	"github.com/arena/arena-cadence/tests/docker"
 	"github.com/onflow/cadence"
 	"github.com/onflow/flow-go-sdk/crypto"
@@ -20,13 +23,8 @@ import (
 )
 
 var (
-	DefaultImage       = "gcr.io/flow-container-registry/emulator:0.19.0"
-	DefaultPort        = "3569"
-	ServiceAccountKey  = "2eae2f31cb5b756151fa11d82949c634b8f28796a711d7eb1e52cc301ed11111"
-	ServiceAccountAddr = "f8d6e0586b0a20c7"
-	// Well known contracts
-	FungibleTokenAddr = "ee82856bf20e2aa6"
-	FlowTokenAddr     = "0ae53cb6e3f42a79"
+	DefaultImage = "gcr.io/flow-container-registry/emulator:0.19.0"
+	DefaultPort  = "3569"
 )
 
 type EmulatorContainer struct {
@@ -37,7 +35,7 @@ type EmulatorContainer struct {
 
 type Emulator struct {
 	Client         *client.Client
-	Privkeys       map[flow.Address]crypto.PrivateKey
+	Keys           signing.Keyring
 	Contracts      map[string]flow.Address
 	ServiceAccount flow.Address
 }
@@ -47,15 +45,18 @@ type Emulator struct {
 // The emulator has no initial state other than several base flow contracts.
 func NewUnit(t *testing.T, port string, dockerLogsOnFail bool) (em *Emulator, teardown func()) {
 
+	// the service account and its key are taken from the project flow.json
+	cfg, serviceAcct, serviceKeys := loadConfig(t)
+
 	// start emulator container
 	// TODO(dave): make port injectable so we can run tests in parallel
 	c := docker.StartContainer(t, DefaultImage, DefaultPort,
 		"-p", fmt.Sprintf("%s:%s", port, port),
 		"-e", fmt.Sprintf("FLOW_PORT=%s", port),
 		"-e", "FLOW_VERBOSE=true",
-		"-e", "FLOW_SERVICEPUBLICKEY=31a053a2003d95760d8fff623aeedcc927022d8e0767972ab507608a5f611636e81857c6c46b048be6f66eddc13f5553627861153f6ce301caf5a056d68efc29",
-		"-e", "FLOW_SERVICEKEYSIGALGO=ECDSA_P256",
-		"-e", "FLOW_SERVICEKEYHASHALGO=SHA3_256",
+		"-e", fmt.Sprintf("FLOW_SERVICEPUBLICKEY=%s", hex.EncodeToString(serviceKeys[0].PrivateKey.PublicKey().Encode())),
+		"-e", fmt.Sprintf("FLOW_SERVICEKEYSIGALGO=%s", serviceKeys[0].PrivateKey.Algorithm()),
+		"-e", fmt.Sprintf("FLOW_SERVICEKEYHASHALGO=%s", serviceKeys[0].HashAlgo),
 	)
 
 	client, err := client.New(fmt.Sprintf(":%s", port), grpc.WithInsecure())
@@ -94,136 +95,66 @@ func NewUnit(t *testing.T, port string, dockerLogsOnFail bool) (em *Emulator, te
 		docker.StopContainer(t, c.ID)
 	}
 
-	// Add service account key and known contracts
-	// TODO(dave): figure out how to inject flow.json to container
-	privkeys := make(map[flow.Address]crypto.PrivateKey)
-	acctKey, _ := crypto.DecodePrivateKeyHex(crypto.ECDSA_P256, ServiceAccountKey)
-	privkeys[flow.HexToAddress(ServiceAccountAddr)] = acctKey
+	// Add service account key and the contracts aliased or deployed on the emulator network
+	keys := signing.Keyring{serviceAcct.Address: serviceKeys}
 
-	contracts := make(map[string]flow.Address)
-	contracts["FungibleToken"] = flow.HexToAddress(FungibleTokenAddr)
-	contracts["FlowToken"] = flow.HexToAddress(FlowTokenAddr)
+	contracts, err := cfg.Addresses("emulator")
+	if err != nil {
+		t.Fatalf("Reading emulator contracts: %v", err)
+	}
 
 	em = &Emulator{
 		Client:         client,
-		Privkeys:       privkeys,
+		Keys:           keys,
 		Contracts:      contracts,
-		ServiceAccount: flow.HexToAddress(ServiceAccountAddr),
+		ServiceAccount: serviceAcct.Address,
 	}
 	return em, teardown
 }
 
-type TxSigners struct {
-	Proposer    flow.Address
-	Payer       flow.Address
-	Authorizers []flow.Address
-}
-
-func (e *Emulator) SignTx(signers TxSigners, tx *flow.Transaction) error {
-	// TODO(dave): this is kinda ugly, but payer is part of the payload, so we can't
-	// defer knowing the payer until the end. Try to think of a clean way to allow
-	// signing to be idempotent. Might be able to pass id upfront but also provide
-	// future resolver for signature similar to wallet spec authorization function impl
-
-	if signers.Payer == flow.EmptyAddress {
-		return fmt.Errorf("Tx payer signer must be specified")
-	}
-
-	// TODO(dave): will need to slightly adjust payer logic if we ever used
-	// keys with fractional signing power
-	// Nothing to do if envelope already signed
-	if len(tx.EnvelopeSignatures) > 0 {
-		return nil
-	}
+// loadConfig reads the nearest flow.json along with the emulator service account
+// and its decoded keys
+func loadConfig(t *testing.T) (*flowconfig.Config, flowconfig.Account, []signing.KeyConfig) {
+	t.Helper()
 
-	// Get current block
-	referenceBlockID, err := e.Client.GetLatestBlock(context.Background(), true)
+	path, err := flowconfig.Find()
 	if err != nil {
-		return fmt.Errorf("GetLatestBlock: %v", err)
+		t.Fatalf("Finding flow config: %v", err)
 	}
-
-	// get updated sequence number for proposal key
-	proposerAcct, err := e.Client.GetAccount(context.Background(), signers.Proposer)
+	cfg, err := flowconfig.Load(path)
 	if err != nil {
-		return fmt.Errorf("GetAccount: %v", err)
+		t.Fatalf("Loading %s: %v", path, err)
 	}
-
-	// finalize payload
-	// TODO(dave): inejectable key_id for each signer role
-	tx.SetPayer(signers.Payer)
-	tx.SetProposalKey(signers.Proposer, proposerAcct.Keys[0].Index, proposerAcct.Keys[0].SequenceNumber)
-	tx.SetReferenceBlockID(referenceBlockID.ID)
-	for _, authorizer := range signers.Authorizers {
-		tx.AddAuthorizer(authorizer)
+	acct, err := cfg.EmulatorServiceAccount()
+	if err != nil {
+		t.Fatalf("Emulator service account: %v", err)
 	}
-
-	// sign payload with proposal key if different than payer
-	if signers.Proposer != flow.EmptyAddress && signers.Proposer != signers.Payer {
-
-		// fetch signing key and sign
-		signingKey := e.Privkeys[signers.Proposer]
-		proposerSigner := crypto.NewInMemorySigner(signingKey, crypto.SHA3_256)
-		if err := tx.SignPayload(signers.Proposer, proposerAcct.Keys[0].Index, proposerSigner); err != nil {
-			return fmt.Errorf("Unable to sign payload with proposer key: %v", err)
-		}
+	if len(acct.Keys) == 0 {
+		t.Fatalf("Emulator service account %s has no keys", acct.Address)
 	}
-
-	// sign payload with each authorizer key different than payer
-	for _, authorizer := range signers.Authorizers {
-		if authorizer != flow.EmptyAddress && authorizer == signers.Payer {
-			continue
-		}
-
-		authorizerAcct, err := e.Client.GetAccount(context.Background(), authorizer)
-		if err != nil {
-			return fmt.Errorf("Unable to get account: %v", err)
-		}
-
-		// fetch signing key and sign
-		signingKey := e.Privkeys[authorizer]
-		authSigner := crypto.NewInMemorySigner(signingKey, crypto.SHA3_256)
-		if err := tx.SignPayload(authorizer, authorizerAcct.Keys[0].Index, authSigner); err != nil {
-			return fmt.Errorf("Unable to sign payload with authorizer key: %v", err)
-		}
-	}
-
-	// Sign envelope with payer
-	payerAcct, err := e.Client.GetAccount(context.Background(), signers.Payer)
+	keys, err := acct.KeyConfigs()
 	if err != nil {
-		return fmt.Errorf("Unable to get Payer account: %v", err)
+		t.Fatalf("Decoding emulator service account keys: %v", err)
 	}
+	return cfg, acct, keys
+}
 
-	// fetch signing key and sign
-	signingKey := e.Privkeys[signers.Payer]
-	payerSigner := crypto.NewInMemorySigner(signingKey, crypto.SHA3_256)
-	if err := tx.SignEnvelope(signers.Payer, payerAcct.Keys[0].Index, payerSigner); err != nil {
-		return fmt.Errorf("Unable to sign payload with Payer key: %v", err)
-	}
+// TxSigners assigns the accounts that sign a transaction
+type TxSigners = signing.Roles
 
-	return nil
+func (e *Emulator) SignTx(signers TxSigners, tx *flow.Transaction) error {
+	return signing.New(e.Client, e.Keys).Sign(context.Background(), signers, tx)
 }
 
+// ExecuteTxWaitForSeal sends tx and waits for it to be sealed. Submission
+// failures are returned as the result error.
 func (e *Emulator) ExecuteTxWaitForSeal(tx *flow.Transaction) *flow.TransactionResult {
-	if err := e.Client.SendTransaction(context.Background(), *tx); err != nil {
-		return &flow.TransactionResult{Error: fmt.Errorf("Sending Tx: %v", err)}
-	}
-
-	result, err := e.Client.GetTransactionResult(context.Background(), tx.ID())
+	s := &submit.Submitter{Client: e.Client}
+	result, err := s.Submit(context.Background(), tx)
 	if err != nil {
-		return &flow.TransactionResult{Error: fmt.Errorf("GetTransactionResult: %v", err)}
-	}
-
-	for result.Status != flow.TransactionStatusSealed {
-		fmt.Println("Waiting for tx to be sealed...")
-		time.Sleep(5 * time.Second)
-		result, err = e.Client.GetTransactionResult(context.Background(), tx.ID())
-		if err != nil {
-			return &flow.TransactionResult{Error: fmt.Errorf("GetTransactionResult: %v", err)}
-		}
+		return &flow.TransactionResult{Error: err}
 	}
-
 	return result
-
 }
 
 const deployContractTemplate = `
@@ -239,11 +170,10 @@ func (e *Emulator) DeployContract(owner flow.Address, name string, source string
 		AddRawArgument(jsoncdc.MustEncode(cadence.NewString(name))).
 		AddRawArgument(jsoncdc.MustEncode(cadence.NewString(hex.EncodeToString([]byte(source)))))
 
-	serviceAcct := flow.HexToAddress(ServiceAccountAddr)
 	signers := TxSigners{
-		Proposer:    serviceAcct,
+		Proposer:    e.ServiceAccount,
 		Authorizers: []flow.Address{owner},
-		Payer:       serviceAcct,
+		Payer:       e.ServiceAccount,
 	}
 	if err := e.SignTx(signers, tx); err != nil {
 		return nil, fmt.Errorf("Failed to sign Tx: %v", err)
@@ -322,7 +252,7 @@ func (e *Emulator) AddAccount() (flow.Address, error) {
 	newAcctAddr := accountCreatedEvent.Address()
 
 	// Track the key of new account to simplify testing
-	e.Privkeys[newAcctAddr] = privkey
+	e.Keys[newAcctAddr] = []signing.KeyConfig{{Index: 0, PrivateKey: privkey, HashAlgo: crypto.SHA3_256}}
 
 	return newAcctAddr, nil
 }
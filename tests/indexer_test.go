@@ -0,0 +1,83 @@
+package tests
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/arena/arena-cadence/lib/go/arenatoken"
+	"github.com/arena/arena-cadence/lib/go/events"
+	"github.com/arena/arena-cadence/lib/go/indexer"
+	"github.com/arena/arena-cadence/lib/go/ledger"
+	"github.com/arena/arena-cadence/tests/emulator"
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go-sdk"
+)
+
+func TestIndexer(t *testing.T) {
+	em, teardown := emulator.NewUnit(t, "3569", *dockerLogsOnFail)
+	defer teardown()
+
+	contractSource := arenatoken.Contract(em.Contracts["FungibleToken"])
+	DeployContract(t, em, em.ServiceAccount, "ArenaToken", contractSource)
+	txRenderer := arenatoken.New(em.Contracts["ArenaToken"], em.Contracts["FungibleToken"])
+
+	amt, _ := cadence.NewUFix64("100.0")
+	tx := txRenderer.MintTokens(em.ServiceAccount, amt)
+	signers := emulator.TxSigners{
+		Proposer:    em.ServiceAccount,
+		Payer:       em.ServiceAccount,
+		Authorizers: []flow.Address{em.ServiceAccount},
+	}
+	em.SignTx(signers, tx)
+	if result := em.ExecuteTxWaitForSeal(tx); result.Error != nil {
+		t.Fatalf("mint_arena tx execution: %v", result.Error)
+	}
+
+	sink, err := indexer.OpenBolt(filepath.Join(t.TempDir(), "index.db"))
+	if err != nil {
+		t.Fatalf("Opening sink: %v", err)
+	}
+	defer sink.Close()
+
+	ix := &indexer.Indexer{Client: em.Client, Decoder: events.For(txRenderer), Sink: sink}
+	height, err := ix.Sync(context.Background())
+	if err != nil {
+		t.Fatalf("Indexing: %v", err)
+	}
+
+	var (
+		kinds   []string
+		records []indexer.Record
+	)
+	err = sink.Records(0, height, func(r indexer.Record) error {
+		kinds = append(kinds, r.Kind)
+		records = append(records, r)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Reading records: %v", err)
+	}
+	expected := []string{"TokensInitialized", "MinterCreated", "TokensMinted", "TokensDeposited"}
+	if len(kinds) != len(expected) {
+		t.Fatalf("Expected records %v, got: %v", expected, kinds)
+	}
+	for i := range expected {
+		if kinds[i] != expected[i] {
+			t.Fatalf("Expected records %v, got: %v", expected, kinds)
+		}
+	}
+
+	// the ledger agrees with the Balance script at the indexed height
+	l := ledger.New(em.Contracts["ArenaToken"])
+	if _, err := l.Apply(records); err != nil {
+		t.Fatalf("Building ledger: %v", err)
+	}
+	divergences, err := l.Check(context.Background(), ledger.ScriptBalance(em.Client, txRenderer, height))
+	if err != nil {
+		t.Fatalf("Checking ledger: %v", err)
+	}
+	if len(divergences) != 0 {
+		t.Fatalf("Ledger diverges from chain: %+v", divergences)
+	}
+}
@@ -24,7 +24,7 @@ func TestAdminActions(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Creating testnet client: %v", err)
 	}
-	adminAddr := flow.HexToAddress("0x0996b5100d5c8ad6")
+	adminAddr := arenaTokenAddr
 	adminPrivkey, err := crypto.DecodePrivateKeyHex(crypto.ECDSA_P256, sampleAdminPrivkey)
 	if err != nil {
 		t.Fatalf("Failed to decode private key: %v", err)
@@ -35,7 +35,7 @@ func TestAdminActions(t *testing.T) {
 		flowclient: flowclient,
 		privkeys:   keys,
 	}
-	txRenderer := arenatoken.New(adminAddr, fungibleTokenAddr)
+	txRenderer := newRenderer(t)
 
 	t.Run("MintToAdmin", func(t *testing.T) {
 		// fetch the current balance
@@ -124,7 +124,7 @@ func TestStandardUserFlow(t *testing.T) {
 		t.Fatalf("Creating testnet client: %v", err)
 	}
 
-	adminAddr := flow.HexToAddress("0x0996b5100d5c8ad6")
+	adminAddr := arenaTokenAddr
 	adminPrivkey, err := crypto.DecodePrivateKeyHex(crypto.ECDSA_P256, sampleAdminPrivkey)
 	if err != nil {
 		t.Fatalf("Failed to decode private key: %v", err)
@@ -145,7 +145,7 @@ func TestStandardUserFlow(t *testing.T) {
 		flowclient: flowclient,
 		privkeys:   keys,
 	}
-	txRenderer := arenatoken.New(adminAddr, fungibleTokenAddr)
+	txRenderer := newRenderer(t)
 
 	t.Run("SetupAccount", func(t *testing.T) {
 
@@ -223,7 +223,7 @@ func TestStandardUserFlowAdminPaysFees(t *testing.T) {
 		t.Fatalf("Creating testnet client: %v", err)
 	}
 
-	adminAddr := flow.HexToAddress("0x0996b5100d5c8ad6")
+	adminAddr := arenaTokenAddr
 	adminPrivkey, err := crypto.DecodePrivateKeyHex(crypto.ECDSA_P256, sampleAdminPrivkey)
 	if err != nil {
 		t.Fatalf("Failed to decode private key: %v", err)
@@ -244,7 +244,7 @@ func TestStandardUserFlowAdminPaysFees(t *testing.T) {
 		flowclient: flowclient,
 		privkeys:   keys,
 	}
-	txRenderer := arenatoken.New(adminAddr, fungibleTokenAddr)
+	txRenderer := newRenderer(t)
 
 	t.Run("SetupAccount", func(t *testing.T) {
 
@@ -314,10 +314,20 @@ func TestStandardUserFlowAdminPaysFees(t *testing.T) {
 
 }
 
+func newRenderer(t *testing.T) *arenatoken.ArenaToken {
+	t.Helper()
+
+	txRenderer, err := arenatoken.ForNetwork(flow.Testnet)
+	if err != nil {
+		t.Fatalf("Creating testnet renderer: %v", err)
+	}
+	return txRenderer
+}
+
 func arenaBalance(t *testing.T, tc *testnetClient, target flow.Address) cadence.UFix64 {
 	t.Helper()
 
-	txRenderer := arenatoken.New(arenaTokenAddr, fungibleTokenAddr)
+	txRenderer := newRenderer(t)
 	balanceScript, args := txRenderer.Balance(target)
 	val, err := tc.flowclient.ExecuteScriptAtLatestBlock(context.Background(), balanceScript, args)
 	if err != nil {